@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"games"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pair - the games of one seed, the candidate having played every seat once
+type pair struct {
+	seed   int64
+	size   int
+	wins   int
+	played int
+	margin float64 // relative score differences summed over the seats
+	failed bool
+}
+
+func main() {
+	var (
+		enginePath = flag.String("engine", "./halite", "path to the halite engine binary")
+		candidate  = flag.String("candidate", "./bot", "command of the build under test")
+		baseline   = flag.String("baseline", "./bot-baseline", "command of the build it has to hold up against")
+		seeds      = flag.Int("seeds", 50, "map seeds to play, each once with the candidate in every seat")
+		players    = flag.Int("players", 2, "players per game, 2 or 4, the other seats are all baseline")
+		sizes      = flag.String("sizes", "32,40,48,56,64", "comma separated map sizes to pick from")
+		turns      = flag.Int("turns", 0, "turn limit per game, zero keeps the engine default")
+		workers    = flag.Int("workers", 0, "games played at once, zero uses every CPU core")
+		seed       = flag.Int64("seed", time.Now().UnixNano(), "seed for the map seeds, fix it to replay a gate run")
+		timeout    = flag.Duration("timeout", 5*time.Minute, "kill a game running longer than this")
+		alpha      = flag.Float64("alpha", 0.05, "significance level of the gate, split evenly over its two one sided tests")
+		maxErrors  = flag.Int("max-errors", 0, "failed games tolerated before the gate fails")
+	)
+	flag.Parse()
+	if *players != 2 && *players != 4 {
+		fatal(fmt.Errorf("-players must be 2 or 4"))
+	}
+	var sizeList []int
+	for _, s := range strings.Split(*sizes, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			fatal(fmt.Errorf("-sizes: %v", err))
+		}
+		sizeList = append(sizeList, v)
+	}
+
+	// the same map once per seat: on the mirrored maps the engine makes, that also plays both starts
+	rng := rand.New(rand.NewSource(*seed))
+	pairs := make([]*pair, *seeds)
+	var matches []games.Match
+	for i := range pairs {
+		pairs[i] = &pair{seed: rng.Int63n(math.MaxInt32), size: sizeList[rng.Intn(len(sizeList))]}
+		for seat := 0; seat < *players; seat++ {
+			m := games.Match{Seed: pairs[i].seed, Size: pairs[i].size, Turns: *turns, Bots: make([]string, *players)}
+			for p := range m.Bots {
+				m.Bots[p] = *baseline
+			}
+			m.Bots[seat] = *candidate
+			matches = append(matches, m)
+		}
+	}
+	fmt.Printf("%s against %s: %d seeds, %d games\n", *candidate, *baseline, *seeds, len(matches))
+	engine := &games.Engine{Path: *enginePath, Timeout: *timeout}
+	played := 0
+	results := engine.PlayAll(matches, *workers, func(r *games.Result) {
+		played++
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+		}
+		if played%20 == 0 || played == len(matches) {
+			fmt.Fprintf(os.Stderr, "%d/%d games played\n", played, len(matches))
+		}
+	})
+
+	wins, total, errors := 0, 0, 0
+	var haliteSum float64
+	for i, r := range results {
+		p := pairs[i / *players]
+		seat := i % *players
+		if r.Err != nil {
+			errors++
+			p.failed = true
+			continue
+		}
+		total++
+		p.played++
+		if r.Won(seat) {
+			wins++
+			p.wins++
+		}
+		best := 0
+		for o, other := range r.Players {
+			if o != seat && other.Score > best {
+				best = other.Score
+			}
+		}
+		diff := float64(r.Players[seat].Score - best)
+		haliteSum += diff
+		p.margin += r.ScoreDiff(seat)
+	}
+	if total == 0 {
+		fatal(fmt.Errorf("every game failed"))
+	}
+
+	// a seed counts for the sign test when the candidate did better or worse than its fair share of wins on it,
+	// and its margin averaged over the seats is one sample of the paired t-test
+	var margins []float64
+	better, worse, even := 0, 0, 0
+	for _, p := range pairs {
+		if p.failed || p.played == 0 {
+			continue
+		}
+		fair := float64(p.played) / float64(*players)
+		switch w := float64(p.wins); {
+		case w > fair:
+			better++
+		case w < fair:
+			worse++
+		default:
+			even++
+		}
+		margins = append(margins, p.margin/float64(p.played))
+	}
+	signLow, signHigh := games.SignTest(better, better+worse)
+	mean, t, tLow, tHigh := games.TTest(margins)
+
+	fmt.Println()
+	fmt.Printf("win rate      %.3f (%d of %d games, %.3f expected for an equal bot)\n", float64(wins)/float64(total), wins, total, 1/float64(*players))
+	fmt.Printf("seeds         %d better, %d worse, %d even\n", better, worse, even)
+	fmt.Printf("sign test     p(worse) %.4f  p(better) %.4f\n", signLow, signHigh)
+	fmt.Printf("score margin  %+.4f relative, %+.0f halite per game\n", mean, haliteSum/float64(total))
+	fmt.Printf("t-test        t %.2f over %d seeds  p(worse) %.4f  p(better) %.4f\n", t, len(margins), tLow, tHigh)
+	if errors > 0 {
+		fmt.Printf("errors        %d games failed\n", errors)
+	}
+	fmt.Println()
+
+	// two tests on the same seeds, each at half of alpha so the chance of a false verdict stays within alpha
+	each := *alpha / 2
+	switch {
+	case errors > *maxErrors:
+		fmt.Printf("FAIL: %d games failed, more than the %d allowed\n", errors, *maxErrors)
+		os.Exit(1)
+	case signLow < each || tLow < each:
+		fmt.Printf("FAIL: the candidate is significantly worse than the baseline at alpha %g (%g per test, Bonferroni)\n", *alpha, each)
+		os.Exit(1)
+	case signHigh < each || tHigh < each:
+		fmt.Printf("PASS: the candidate is significantly better than the baseline at alpha %g (%g per test, Bonferroni)\n", *alpha, each)
+	default:
+		fmt.Printf("PASS: no significant difference at alpha %g (%g per test, Bonferroni), play more seeds to tell them apart\n", *alpha, each)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "abtest:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,166 @@
+package games
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MapSizes - Map sizes the official engine plays on
+var MapSizes = []int{32, 40, 48, 56, 64}
+
+// Engine - Local halite engine binary used to play matches
+type Engine struct {
+	Path      string        // path to the halite binary
+	ReplayDir string        // replays are written here when set, and skipped otherwise
+	Timeout   time.Duration // a match running longer than this is killed, zero means no limit
+}
+
+// Match - One game between bots. Bots are shell commands as the engine expects them, player i runs Bots[i]
+type Match struct {
+	Seed  int64
+	Size  int
+	Bots  []string
+	Turns int // turn limit, zero keeps the engine default
+}
+
+// PlayerResult - How one player finished a match
+type PlayerResult struct {
+	Rank  int `json:"rank"`
+	Score int `json:"score"`
+}
+
+// Result - Outcome of a match. Players is indexed like Match.Bots
+type Result struct {
+	Match   Match
+	Players []PlayerResult
+	Replay  string
+	Err     error
+}
+
+// Won - Checks if the player finished first
+func (r *Result) Won(player int) bool {
+	return r.Err == nil && r.Players[player].Rank == 1
+}
+
+// ScoreDiff - Score of the player minus the best score among the others, relative to the sum of both
+func (r *Result) ScoreDiff(player int) float64 {
+	if r.Err != nil {
+		return 0
+	}
+	best := 0
+	for i, p := range r.Players {
+		if i != player && p.Score > best {
+			best = p.Score
+		}
+	}
+	own := r.Players[player].Score
+	if own+best == 0 {
+		return 0
+	}
+	return float64(own-best) / float64(own+best)
+}
+
+// engineOutput - the parts of --results-as-json we use
+type engineOutput struct {
+	Replay string                  `json:"replay"`
+	Stats  map[string]PlayerResult `json:"stats"`
+}
+
+// Play - Runs a single match and waits for the result
+func (e *Engine) Play(m Match) *Result {
+	res := &Result{Match: m}
+	args := []string{
+		"--results-as-json",
+		"--no-logs",
+		"--seed", strconv.FormatInt(m.Seed, 10),
+		"--width", strconv.Itoa(m.Size),
+		"--height", strconv.Itoa(m.Size),
+	}
+	if e.ReplayDir != "" {
+		args = append(args, "--replay-directory", e.ReplayDir)
+	} else {
+		args = append(args, "--no-replay")
+	}
+	if m.Turns > 0 {
+		args = append(args, "--turn-limit", strconv.Itoa(m.Turns))
+	}
+	args = append(args, m.Bots...)
+
+	ctx := context.Background()
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		res.Err = fmt.Errorf("games: seed %d size %d: %v: %s", m.Seed, m.Size, err, lastLine(stderr.Bytes()))
+		return res
+	}
+	var out engineOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		res.Err = fmt.Errorf("games: seed %d size %d: bad engine output: %v", m.Seed, m.Size, err)
+		return res
+	}
+	res.Replay = out.Replay
+	res.Players = make([]PlayerResult, len(m.Bots))
+	for i := range m.Bots {
+		p, ok := out.Stats[strconv.Itoa(i)]
+		if !ok {
+			res.Err = fmt.Errorf("games: seed %d size %d: no result for player %d", m.Seed, m.Size, i)
+			return res
+		}
+		res.Players[i] = p
+	}
+	return res
+}
+
+// PlayAll - Runs the matches on a pool of workers, one per CPU core when workers is zero or less.
+// Results come back in the same order as the matches. done is called after every match when not nil
+func (e *Engine) PlayAll(matches []Match, workers int, done func(*Result)) []*Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make([]*Result, len(matches))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = e.Play(matches[i])
+				if done != nil {
+					mu.Lock()
+					done(results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range matches {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func lastLine(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[i+1:]
+	}
+	return string(b)
+}
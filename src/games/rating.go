@@ -0,0 +1,133 @@
+package games
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Outcome - One pairwise result taken from a match: Winner finished ahead of Loser, or level with it on a Draw
+type Outcome struct {
+	Winner int
+	Loser  int
+	Draw   bool
+}
+
+// Outcomes - Splits a match into a pairwise outcome for every two seats, seat i being played by entrants[i].
+// Seats taken by the same entrant are not compared, seats sharing a rank are a draw
+func Outcomes(r *Result, entrants []int) []Outcome {
+	if r.Err != nil {
+		return nil
+	}
+	var out []Outcome
+	for i := range r.Players {
+		for j := i + 1; j < len(r.Players); j++ {
+			if entrants[i] == entrants[j] {
+				continue
+			}
+			switch ri, rj := r.Players[i].Rank, r.Players[j].Rank; {
+			case ri < rj:
+				out = append(out, Outcome{entrants[i], entrants[j], false})
+			case ri > rj:
+				out = append(out, Outcome{entrants[j], entrants[i], false})
+			default:
+				out = append(out, Outcome{entrants[i], entrants[j], true})
+			}
+		}
+	}
+	return out
+}
+
+// Ratings - Elo ratings of n entrants fitted to the outcomes as a Bradley-Terry model, centred on 1500. Every
+// pair that met gets half a win each on top, so an unbeaten entrant still gets a finite rating. A draw is half a
+// win for each side
+func Ratings(n int, outcomes []Outcome) []float64 {
+	wins := make([]float64, n)
+	games := make([][]float64, n)
+	for i := range games {
+		games[i] = make([]float64, n)
+	}
+	for _, o := range outcomes {
+		if games[o.Winner][o.Loser] == 0 {
+			wins[o.Winner] += 0.5
+			wins[o.Loser] += 0.5
+			games[o.Winner][o.Loser]++
+			games[o.Loser][o.Winner]++
+		}
+		if o.Draw {
+			wins[o.Winner] += 0.5
+			wins[o.Loser] += 0.5
+		} else {
+			wins[o.Winner]++
+		}
+		games[o.Winner][o.Loser]++
+		games[o.Loser][o.Winner]++
+	}
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+	// minorization-maximization updates, converge in a few dozen rounds for tournament sized inputs
+	for round := 0; round < 1000; round++ {
+		change := 0.0
+		next := make([]float64, n)
+		for i := range next {
+			denom := 0.0
+			for j := range next {
+				if games[i][j] > 0 {
+					denom += games[i][j] / (strength[i] + strength[j])
+				}
+			}
+			if denom == 0 {
+				next[i] = strength[i]
+				continue
+			}
+			next[i] = wins[i] / denom
+		}
+		// normalise to a geometric mean of 1 so the ratings are centred
+		logSum := 0.0
+		for _, s := range next {
+			logSum += math.Log(s)
+		}
+		scale := math.Exp(logSum / float64(n))
+		for i := range next {
+			next[i] /= scale
+			change = math.Max(change, math.Abs(next[i]-strength[i]))
+		}
+		strength = next
+		if change < 1e-9 {
+			break
+		}
+	}
+	ratings := make([]float64, n)
+	for i, s := range strength {
+		ratings[i] = 1500 + 400*math.Log10(s)
+	}
+	return ratings
+}
+
+// RatingIntervals - Bootstrap confidence intervals of the ratings: the matches are resampled with replacement
+// rounds times and the ratings refitted. Returns the lower and upper bounds at the given level, e.g. 0.95
+func RatingIntervals(n int, matches [][]Outcome, rounds int, level float64, rng *rand.Rand) ([]float64, []float64) {
+	samples := make([][]float64, n)
+	for r := 0; r < rounds; r++ {
+		var outcomes []Outcome
+		for range matches {
+			outcomes = append(outcomes, matches[rng.Intn(len(matches))]...)
+		}
+		for i, rating := range Ratings(n, outcomes) {
+			samples[i] = append(samples[i], rating)
+		}
+	}
+	lo, hi := make([]float64, n), make([]float64, n)
+	for i, s := range samples {
+		if len(s) == 0 {
+			continue
+		}
+		sort.Float64s(s)
+		tail := (1 - level) / 2
+		lo[i] = s[int(tail*float64(len(s)-1))]
+		hi[i] = s[int(math.Ceil((1-tail)*float64(len(s)-1)))]
+	}
+	return lo, hi
+}
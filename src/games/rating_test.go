@@ -0,0 +1,90 @@
+package games
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranks    []int
+		entrants []int
+		want     []Outcome
+	}{
+		{"two players", []int{2, 1}, []int{0, 1}, []Outcome{{1, 0, false}}},
+		{"four players", []int{3, 1, 4, 2}, []int{0, 1, 2, 3}, []Outcome{
+			{1, 0, false}, {0, 2, false}, {3, 0, false}, {1, 2, false}, {1, 3, false}, {3, 2, false},
+		}},
+		{"same entrant twice", []int{1, 2, 3}, []int{5, 5, 7}, []Outcome{{5, 7, false}, {5, 7, false}}},
+		{"tie", []int{1, 1}, []int{0, 1}, []Outcome{{0, 1, true}}},
+	}
+	for _, tt := range tests {
+		r := &Result{}
+		for _, rank := range tt.ranks {
+			r.Players = append(r.Players, PlayerResult{Rank: rank})
+		}
+		if got := Outcomes(r, tt.entrants); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Outcomes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRatings(t *testing.T) {
+	// with the half win every pair gets on top, A beating B once, B beating C once and A beating C four times
+	// is exactly what strengths 9, 3 and 1 predict, so those are the fitted strengths
+	outcomes := []Outcome{{0, 1, false}, {1, 2, false}}
+	for i := 0; i < 4; i++ {
+		outcomes = append(outcomes, Outcome{0, 2, false})
+	}
+	got := Ratings(3, outcomes)
+	want := []float64{1500 + 400*math.Log10(3), 1500, 1500 - 400*math.Log10(3)}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Errorf("Ratings()[%d] = %.6f, want %.6f", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRatingsDraws(t *testing.T) {
+	got := Ratings(2, []Outcome{{0, 1, true}, {0, 1, true}, {1, 0, true}})
+	for i, r := range got {
+		if math.Abs(r-1500) > 1e-6 {
+			t.Errorf("Ratings()[%d] = %.6f after nothing but draws, want 1500", i, r)
+		}
+	}
+	// with the half win on top, a win and a draw against the same opponent is 2 wins to 1, twice as strong
+	got = Ratings(2, []Outcome{{0, 1, false}, {0, 1, true}})
+	if diff := got[0] - got[1]; math.Abs(diff-400*math.Log10(2)) > 1e-6 {
+		t.Errorf("rating gap after a win and a draw = %.6f, want %.6f", diff, 400*math.Log10(2))
+	}
+}
+
+func TestRatingIntervals(t *testing.T) {
+	match := []Outcome{{0, 1, false}, {1, 2, false}, {0, 2, false}}
+	// resampling the same match over and over always gives the same fit, so the interval closes on it
+	same := [][]Outcome{match, match, match}
+	lo, hi := RatingIntervals(3, same, 50, 0.95, rand.New(rand.NewSource(1)))
+	ratings := Ratings(3, append(append(append([]Outcome(nil), match...), match...), match...))
+	for i := range ratings {
+		if math.Abs(lo[i]-ratings[i]) > 1e-6 || math.Abs(hi[i]-ratings[i]) > 1e-6 {
+			t.Errorf("interval %d = %.3f..%.3f, want %.3f", i, lo[i], hi[i], ratings[i])
+		}
+	}
+
+	// mixed matches spread the interval around the fitted rating
+	mixed := [][]Outcome{match, {{1, 0, false}}, {{2, 1, false}}, match, match}
+	var all []Outcome
+	for _, m := range mixed {
+		all = append(all, m...)
+	}
+	ratings = Ratings(3, all)
+	lo, hi = RatingIntervals(3, mixed, 200, 0.9, rand.New(rand.NewSource(1)))
+	for i := range ratings {
+		if lo[i] > ratings[i] || hi[i] < ratings[i] || lo[i] == hi[i] {
+			t.Errorf("interval %d = %.3f..%.3f does not spread around %.3f", i, lo[i], hi[i], ratings[i])
+		}
+	}
+}
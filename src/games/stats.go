@@ -0,0 +1,125 @@
+package games
+
+import "math"
+
+// SignTest - One sided exact binomial test of k successes out of n fair coin flips. Returns the probability of
+// k or fewer successes and of k or more
+func SignTest(k, n int) (lower, upper float64) {
+	if n == 0 {
+		return 1, 1
+	}
+	for i := 0; i <= n; i++ {
+		// log of C(n, i) / 2^n
+		lg1, _ := math.Lgamma(float64(n + 1))
+		lg2, _ := math.Lgamma(float64(i + 1))
+		lg3, _ := math.Lgamma(float64(n - i + 1))
+		p := math.Exp(lg1 - lg2 - lg3 - float64(n)*math.Ln2)
+		if i <= k {
+			lower += p
+		}
+		if i >= k {
+			upper += p
+		}
+	}
+	return math.Min(lower, 1), math.Min(upper, 1)
+}
+
+// TTest - One sample t-test of the mean of xs against zero, for paired differences. Returns the mean, the t
+// statistic and the one sided probabilities of a mean this low and this high if the true mean were zero
+func TTest(xs []float64) (mean, t, lower, upper float64) {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0, 0, 1, 1
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= n
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= n - 1
+	if variance == 0 {
+		switch {
+		case mean < 0:
+			return mean, math.Inf(-1), 0, 1
+		case mean > 0:
+			return mean, math.Inf(1), 1, 0
+		}
+		return mean, 0, 1, 1
+	}
+	t = mean / math.Sqrt(variance/n)
+	lower = studentCDF(t, n-1)
+	return mean, t, lower, 1 - lower
+}
+
+// studentCDF - P(T <= t) for Student's t distribution with df degrees of freedom
+func studentCDF(t, df float64) float64 {
+	x := df / (df + t*t)
+	tail := 0.5 * incompleteBeta(df/2, 0.5, x)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// incompleteBeta - regularized incomplete beta function I_x(a, b), evaluated with its continued fraction
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// the fraction converges quickly on this side, use the symmetry I_x(a, b) = 1 - I_1-x(b, a) on the other
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(b, a, 1-x)/b
+	}
+	return front * betaFraction(a, b, x) / a
+}
+
+// betaFraction - Lentz's method for the continued fraction of the incomplete beta function
+func betaFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		m2 := 2 * m
+		num := m * (b - m) * x / ((a + m2 - 1) * (a + m2))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		num = -(a + m) * (a + b + m) * x / ((a + m2) * (a + m2 + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-12 {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,86 @@
+package games
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSignTest(t *testing.T) {
+	tests := []struct {
+		k, n         int
+		lower, upper float64
+	}{
+		// P(X <= 2) = (1 + 10 + 45) / 1024 and P(X >= 2) = 1 - 11/1024
+		{2, 10, 56.0 / 1024, 1013.0 / 1024},
+		{5, 10, 638.0 / 1024, 638.0 / 1024},
+		{0, 4, 1.0 / 16, 1},
+		{4, 4, 1, 1.0 / 16},
+		{0, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		lower, upper := SignTest(tt.k, tt.n)
+		if math.Abs(lower-tt.lower) > 1e-12 || math.Abs(upper-tt.upper) > 1e-12 {
+			t.Errorf("SignTest(%d, %d) = %.10f, %.10f, want %.10f, %.10f", tt.k, tt.n, lower, upper, tt.lower, tt.upper)
+		}
+	}
+}
+
+func TestStudentCDF(t *testing.T) {
+	tests := []struct {
+		t, df, want float64
+	}{
+		// one degree of freedom is the Cauchy distribution, 1/2 + atan(t)/pi
+		{1, 1, 0.75},
+		{-1, 1, 0.25},
+		{3, 1, 0.5 + math.Atan(3)/math.Pi},
+		// two degrees of freedom have the closed form 1/2 + t / (2 sqrt(2 + t^2))
+		{1.5, 2, 0.5 + 1.5/(2*math.Sqrt(2+1.5*1.5))},
+		// quantiles from the usual tables
+		{2.015048, 5, 0.95},
+		{2.570582, 5, 0.975},
+		{-2.570582, 5, 0.025},
+		{1.697261, 30, 0.95},
+		{2.042272, 30, 0.975},
+		{0, 30, 0.5},
+	}
+	for _, tt := range tests {
+		if got := studentCDF(tt.t, tt.df); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("studentCDF(%g, %g) = %.8f, want %.8f", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestIncompleteBeta(t *testing.T) {
+	tests := []struct {
+		a, b, x, want float64
+	}{
+		{1, 1, 0.3, 0.3},
+		{2, 1, 0.5, 0.25},
+		{1, 2, 0.5, 0.75},
+		{2, 2, 0.5, 0.5},
+		{3, 5, 0, 0},
+		{3, 5, 1, 1},
+	}
+	for _, tt := range tests {
+		if got := incompleteBeta(tt.a, tt.b, tt.x); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("incompleteBeta(%g, %g, %g) = %.12f, want %.12f", tt.a, tt.b, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestTTest(t *testing.T) {
+	// mean 2, standard deviation 1 over three samples: t = 2 sqrt(3) on two degrees of freedom
+	mean, stat, lower, upper := TTest([]float64{1, 2, 3})
+	wantT := 2 * math.Sqrt(3)
+	wantLower := 0.5 + wantT/(2*math.Sqrt(2+wantT*wantT))
+	if mean != 2 || math.Abs(stat-wantT) > 1e-12 || math.Abs(lower-wantLower) > 1e-9 || math.Abs(upper-(1-wantLower)) > 1e-9 {
+		t.Errorf("TTest = %g, %g, %g, %g, want 2, %g, %g, %g", mean, stat, lower, upper, wantT, wantLower, 1-wantLower)
+	}
+
+	if _, _, lower, upper := TTest([]float64{0.5}); lower != 1 || upper != 1 {
+		t.Errorf("TTest of one sample = %g, %g, want 1, 1", lower, upper)
+	}
+	if _, stat, lower, upper := TTest([]float64{-1, -1, -1}); !math.IsInf(stat, -1) || lower != 0 || upper != 1 {
+		t.Errorf("TTest of constant negative samples = %g, %g, %g, want -Inf, 0, 1", stat, lower, upper)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unlimited - turn and analysis budget given to the bot, so a slow machine can't make it cut planning short
+const unlimited = "600000"
+
+// play - runs the bot over a recorded game and returns its commands, one line per turn starting at turn 1
+func play(ctx context.Context, bot, game string, seed int64, extra []string) ([]string, error) {
+	args := []string{"-seed", strconv.FormatInt(seed, 10), "-log-format", "none", "-replay-input", game,
+		"-turn_soft_millis", unlimited, "-turn_hard_millis", unlimited, "-init_analysis_millis", unlimited}
+	cmd := exec.CommandContext(ctx, bot, append(args, extra...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v\n%s", game, err, stderr.String())
+	}
+	var turns []string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	// the first line is the bot name sent with Ready
+	for first := true; sc.Scan(); first = false {
+		if !first {
+			turns = append(turns, normalize(sc.Text()))
+		}
+	}
+	return turns, sc.Err()
+}
+
+// normalize - the commands of a turn one after the other and sorted, the engine does not care about their order
+func normalize(line string) string {
+	tokens := strings.Fields(line)
+	var commands []string
+	for i := 0; i < len(tokens); {
+		n := 1
+		switch tokens[i] {
+		case "m":
+			n = 3
+		case "c":
+			n = 2
+		}
+		if i+n > len(tokens) {
+			n = len(tokens) - i
+		}
+		commands = append(commands, strings.Join(tokens[i:i+n], " "))
+		i += n
+	}
+	sort.Strings(commands)
+	return strings.Join(commands, ", ")
+}
+
+func readGolden(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var turns []string
+	for i, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		prefix := fmt.Sprintf("turn %d:", len(turns)+1)
+		if !strings.HasPrefix(line, prefix) {
+			return nil, fmt.Errorf("%s:%d: expected %q", path, i+1, prefix)
+		}
+		turns = append(turns, strings.TrimSpace(strings.TrimPrefix(line, prefix)))
+	}
+	return turns, nil
+}
+
+func writeGolden(path string, seed int64, extra []string, turns []string) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# seed %d", seed)
+	if len(extra) > 0 {
+		fmt.Fprintf(&b, ", bot flags %s", strings.Join(extra, " "))
+	}
+	fmt.Fprintln(&b)
+	for i, t := range turns {
+		fmt.Fprintf(&b, "turn %d: %s\n", i+1, t)
+	}
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
+
+// compare - prints the turns where got differs from want and returns how many did
+func compare(name string, want, got []string, show int) int {
+	changed, first := 0, 0
+	n := len(want)
+	if len(got) > n {
+		n = len(got)
+	}
+	for i := 0; i < n; i++ {
+		w, g := "(no turn)", "(no turn)"
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w == g {
+			continue
+		}
+		changed++
+		if first == 0 {
+			first = i + 1
+		}
+		if changed <= show {
+			fmt.Printf("    turn %d\n      want %s\n      got  %s\n", i+1, w, g)
+		}
+	}
+	if changed > show {
+		fmt.Printf("    ... and %d more\n", changed-show)
+	}
+	if changed > 0 {
+		fmt.Printf("FAIL %s: %d of %d turns changed, the first at turn %d\n", name, changed, n, first)
+	} else {
+		fmt.Printf("ok   %s: %d turns\n", name, n)
+	}
+	return changed
+}
+
+func main() {
+	var (
+		bot     = flag.String("bot", "./bot", "bot binary to check")
+		dir     = flag.String("dir", "golden", "directory with recorded games (*.input) and their golden files (*.golden)")
+		seed    = flag.Int64("seed", 1, "bot rng seed")
+		botArgs = flag.String("args", "", "extra flags for the bot, e.g. \"-strategy safe\"")
+		update  = flag.Bool("update", false, "write the golden files from this run instead of comparing")
+		show    = flag.Int("show", 5, "changed turns printed per game")
+		timeout = flag.Duration("timeout", 5*time.Minute, "time allowed per game")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] [game.input...]\n\nPlays recorded games (see the bot's -record-input) through the bot and compares its commands on every turn with the golden files.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	games := flag.Args()
+	if len(games) == 0 {
+		var err error
+		if games, err = filepath.Glob(filepath.Join(*dir, "*.input")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		sort.Strings(games)
+	}
+	if len(games) == 0 {
+		fmt.Fprintf(os.Stderr, "no recorded games in %s\n", *dir)
+		os.Exit(2)
+	}
+	extra := strings.Fields(*botArgs)
+
+	failed := 0
+	for _, game := range games {
+		name := strings.TrimSuffix(filepath.Base(game), filepath.Ext(game))
+		golden := strings.TrimSuffix(game, filepath.Ext(game)) + ".golden"
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		got, err := play(ctx, *bot, game, *seed, extra)
+		cancel()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if *update {
+			if err := writeGolden(golden, *seed, extra, got); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			fmt.Printf("wrote %s: %d turns\n", golden, len(got))
+			continue
+		}
+		want, err := readGolden(golden)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if compare(name, want, got, *show) > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d games changed, rerun with -update to accept the new behavior\n", failed, len(games))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,19 @@
+package helper
+
+import (
+	"hlt"
+	"sort"
+)
+
+// SortedShips - Returns the ships ordered by ID, so decisions that depend on the order ships are handled in are
+// the same from run to run
+func SortedShips(ships map[int]*hlt.Ship) []*hlt.Ship {
+	sorted := make([]*hlt.Ship, 0, len(ships))
+	for _, s := range ships {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].E.ID() < sorted[j].E.ID()
+	})
+	return sorted
+}
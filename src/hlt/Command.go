@@ -36,3 +36,18 @@ type Move struct {
 func (m Move) CommandString() string {
 	return fmt.Sprintf("m %d %c", m.id, m.direction.charValue)
 }
+
+// ShipID - Returns the ID of the ship being transformed
+func (t TransformToDropoff) ShipID() int {
+	return t.id
+}
+
+// ShipID - Returns the ID of the ship being moved
+func (m Move) ShipID() int {
+	return m.id
+}
+
+// Direction - Returns the direction the ship is moved in
+func (m Move) Direction() *Direction {
+	return m.direction
+}
@@ -50,6 +50,14 @@ func Still() *Direction {
 	return &Direction{STILL}
 }
 
+// Equals - Compares the direction against a given direction
+func (d *Direction) Equals(o *Direction) bool {
+	if o == nil {
+		return false
+	}
+	return d.charValue == o.charValue
+}
+
 // Inverse - Returns the opposite of a direction
 func (d *Direction) Inverse() (*Direction, error) {
 	switch d.charValue {
@@ -18,6 +18,11 @@ func (e *Entity) ID() int {
 	return e.id
 }
 
+// PlayerID - Returns the ID of the player that owns the Entity
+func (e *Entity) PlayerID() int {
+	return e.playerID
+}
+
 /*********************************************************************************/
 
 // Dropoff - Dropoff structure
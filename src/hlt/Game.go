@@ -20,12 +20,22 @@ func (g *Game) String() string {
 	return fmt.Sprintf("Game{NumPlayers=%d,Me=%s,players=%d,Map=%s}", g.numPlayers, g.Me.String(), len(g.players), g.Map.String())
 }
 
+// NumPlayers - Returns the number of players in the game
+func (g *Game) NumPlayers() int {
+	return g.numPlayers
+}
+
+// Players - Returns all players in the game, indexed by player ID
+func (g *Game) Players() []*Player {
+	return g.players
+}
+
 // Ready - When run, notifies the server that the bot is ready to start
 func (g *Game) Ready(name string) {
 	fmt.Println(name)
 }
 
-// NewGame - Creates a new game for bot named by the parameter
+// NewGame - Creates a new game from the game header of the input, nil when the input ends before the header does
 func NewGame() *Game {
 	var input = input.GetInstance()
 	if !input.Scanner.Scan() {
@@ -34,22 +44,33 @@ func NewGame() *Game {
 
 	var constantsString = input.Scanner.Text()
 	gameconfig.Init(constantsString)
-	var numPlayers, _ = input.GetInt()
-	var myID, _ = input.GetInt()
+	var numPlayers, err = input.GetInt()
+	var myID, idErr = input.GetInt()
+	if err != nil || idErr != nil || numPlayers <= 0 || myID < 0 || myID >= numPlayers {
+		return nil
+	}
 	var players = make([]*Player, numPlayers)
 	for i := range players {
 		players[i] = NewPlayer()
 	}
 	var gameMap = GenerateGameMap()
+	if gameMap == nil {
+		return nil
+	}
 	var me = players[myID]
 	return &Game{numPlayers, me, players, gameMap, 0}
 }
 
-// UpdateFrame - Runs a single turn in the game
-func (g *Game) UpdateFrame() {
+// UpdateFrame - Runs a single turn in the game. Returns io.EOF once the game runner closed the connection
+func (g *Game) UpdateFrame() error {
 	var logger = log.GetInstance()
 	var input = input.GetInstance()
-	g.TurnNumber, _ = input.GetInt()
+	var turn, err = input.GetInt()
+	if err != nil {
+		return err
+	}
+	g.TurnNumber = turn
+	logger.SetTurn(g.TurnNumber)
 	logger.Printf("=============== TURN %d ================\n", g.TurnNumber)
 	for i := range g.players {
 		// Player ID variable being read here. It isn't used, so we can't assign it in Go without error, but we still need to consume it from the input
@@ -71,6 +92,7 @@ func (g *Game) UpdateFrame() {
 			g.Map.AtEntity(dropoff.E).structure = dropoff.E
 		}
 	}
+	return nil
 }
 
 // EndTurn -
@@ -25,6 +25,16 @@ func NewGameMap(width int, height int) *GameMap {
 	return &GameMap{width, height, cells}
 }
 
+// Width - Returns the width of the map
+func (gm *GameMap) Width() int {
+	return gm.width
+}
+
+// Height - Returns the height of the map
+func (gm *GameMap) Height() int {
+	return gm.height
+}
+
 // AtPosition - Returns the mapcell at the given position
 func (gm *GameMap) AtPosition(position *Position) *MapCell {
 	return gm.Cells[position.y][position.x]
@@ -35,15 +45,21 @@ func (gm *GameMap) AtEntity(entity *Entity) *MapCell {
 	return gm.AtPosition(entity.Pos)
 }
 
-// GenerateGameMap - Creates new game map from input data
+// GenerateGameMap - Creates new game map from input data, nil when the input ends before the map does
 func GenerateGameMap() *GameMap {
 	var input = input.GetInstance()
-	var width, _ = input.GetInt()
-	var height, _ = input.GetInt()
+	var width, err = input.GetInt()
+	var height, heightErr = input.GetInt()
+	if err != nil || heightErr != nil || width <= 0 || height <= 0 {
+		return nil
+	}
 	var gameMap = NewGameMap(width, height)
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			var halite, _ = input.GetInt()
+			var halite, err = input.GetInt()
+			if err != nil {
+				return nil
+			}
 			gameMap.Cells[y][x] = &MapCell{&Position{x, y}, halite, nil, nil}
 		}
 	}
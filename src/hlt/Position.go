@@ -8,6 +8,21 @@ type Position struct {
 	y int
 }
 
+// NewPosition - Creates a new position at the given coordinates
+func NewPosition(x int, y int) *Position {
+	return &Position{x, y}
+}
+
+// X - Returns the x coordinate of the position
+func (p *Position) X() int {
+	return p.x
+}
+
+// Y - Returns the y coordinate of the position
+func (p *Position) Y() int {
+	return p.y
+}
+
 func (p *Position) String() string {
 	return fmt.Sprintf("Pos{x=%d,y=%d}", p.x, p.y)
 }
@@ -11,18 +11,18 @@ import (
 
 // Game setting keys
 const (
-	ShipCost                 string = "NEW_ENTITY_ENERGY_COST"    /** The cost to build a single ship. */
-	DropoffCost              string = "DROPOFF_COST"              /** The cost to build a dropoff. */
-	MaxHalite                string = "MAX_ENERGY"                /** The maximum amount of halite a ship can carry. */
-	MaxTurns                 string = "MAX_TURNS"                 /** The maximum number of turns a game can last. */
-	ExtractRatio             string = "EXTRACT_RATIO"             /** 1/EXTRACT_RATIO halite (rounded) is collected from a square per turn. */
-	MoveCostRatio            string = "MOVE_COST_RATIO"           /** 1/MOVE_COST_RATIO halite (rounded) is needed to move off a cell. */
-	InspirationEnabled       string = "INSPIRATION_ENABLED"       /** Whether inspiration is enabled. */
-	InspirationRadius        string = "INSPIRATION_RADIUS"        /** A ship is inspired if at least INSPIRATION_SHIP_COUNT opponent ships are within this Manhattan distance. */
-	InspirationShipCount     string = "INSPIRATION_SHIP_COUNT"    /** A ship is inspired if at least this many opponent ships are within INSPIRATION_RADIUS distance. */
-	InspiredExtractRatio     string = "INSPIRED_EXTRACT_RATIO"    /** An inspired ship mines 1/X halite from a cell per turn instead. */
-	InspiredBonusMultiplayer string = "INSPIRED_BONUS_MULTIPLIER" /** An inspired ship that removes Y halite from a cell collects X*Y additional halite. */
-	InspiredMoveCostRatio    string = "INSPIRED_MOVE_COST_RATIO"  /** An inspired ship instead spends 1/X% halite to move. */
+	ShipCost                string = "NEW_ENTITY_ENERGY_COST"    /** The cost to build a single ship. */
+	DropoffCost             string = "DROPOFF_COST"              /** The cost to build a dropoff. */
+	MaxHalite               string = "MAX_ENERGY"                /** The maximum amount of halite a ship can carry. */
+	MaxTurns                string = "MAX_TURNS"                 /** The maximum number of turns a game can last. */
+	ExtractRatio            string = "EXTRACT_RATIO"             /** 1/EXTRACT_RATIO halite (rounded) is collected from a square per turn. */
+	MoveCostRatio           string = "MOVE_COST_RATIO"           /** 1/MOVE_COST_RATIO halite (rounded) is needed to move off a cell. */
+	InspirationEnabled      string = "INSPIRATION_ENABLED"       /** Whether inspiration is enabled. */
+	InspirationRadius       string = "INSPIRATION_RADIUS"        /** A ship is inspired if at least INSPIRATION_SHIP_COUNT opponent ships are within this Manhattan distance. */
+	InspirationShipCount    string = "INSPIRATION_SHIP_COUNT"    /** A ship is inspired if at least this many opponent ships are within INSPIRATION_RADIUS distance. */
+	InspiredExtractRatio    string = "INSPIRED_EXTRACT_RATIO"    /** An inspired ship mines 1/X halite from a cell per turn instead. */
+	InspiredBonusMultiplier string = "INSPIRED_BONUS_MULTIPLIER" /** An inspired ship that removes Y halite from a cell collects X*Y additional halite. */
+	InspiredMoveCostRatio   string = "INSPIRED_MOVE_COST_RATIO"  /** An inspired ship instead spends 1/X% halite to move. */
 )
 
 // Constants - Holds all of the game constants
@@ -63,6 +63,13 @@ func Init(inputString string) error {
 	return loadErr
 }
 
+// Reset - Forgets the loaded constants so the next Init reads them again, for processes that set up more than one game
+func Reset() {
+	constantsInstance = nil
+	loadErr = nil
+	once = sync.Once{}
+}
+
 // GetInt - Returns value from the map as an int
 func (c Constants) GetInt(key string) (int, error) {
 	return strconv.Atoi(c.values[key])
@@ -2,7 +2,6 @@ package input
 
 import (
 	"bufio"
-	"hlt/log"
 	"io"
 	"os"
 	"regexp"
@@ -35,6 +34,12 @@ func GetInstance() *Input {
 	return instance
 }
 
+// SetSource - Reads the game from r instead of stdin, e.g. a recorded game. Call before the game is created
+func SetSource(r io.Reader) {
+	once.Do(func() {})
+	instance = &Input{bufio.NewScanner(r), nil, -1}
+}
+
 func deleteEmpty(s []string) []string {
 	var r []string
 	for _, str := range s {
@@ -45,10 +50,13 @@ func deleteEmpty(s []string) []string {
 	return r
 }
 
+// readLine - reads the next line into the buffer, io.EOF once the game runner closed the connection
 func (i *Input) readLine() error {
 	if !i.Scanner.Scan() {
-		log.GetInstance().Printf("Input connection from server closed. Exiting...")
-		os.Exit(0)
+		if err := i.Scanner.Err(); err != nil {
+			return err
+		}
+		return io.EOF
 	}
 	var nextLine = i.Scanner.Text()
 	splitter := regexp.MustCompile(" +")
@@ -60,8 +68,7 @@ func (i *Input) readLine() error {
 // GetString -
 func (i *Input) GetString() (string, error) {
 	if i.Buffer == nil || i.Position >= len(i.Buffer) {
-		var err = i.readLine()
-		if err == io.EOF {
+		if err := i.readLine(); err != nil {
 			return "", err
 		}
 	}
@@ -4,15 +4,130 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Level - How important a log line is. Lines below the level of their subsystem are dropped
+type Level int
+
+// Log levels, lowest first
+const (
+	Debug = Level(iota)
+	Info
+	Warn
+	Error
+	Off
+)
+
+var levelNames = [...]string{"debug", "info", "warn", "error", "off"}
+
+func (l Level) String() string {
+	if l >= 0 && int(l) < len(levelNames) {
+		return levelNames[l]
+	}
+	return "unknown"
+}
+
+// ParseLevel - Parses a level name like "info" or "off"
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("log: unknown level %q, expected one of %s", name, strings.Join(levelNames[:], ", "))
+}
+
+// ParseLevels - Parses a level spec like "info,move=debug,spawn=off": a default level followed by levels
+// for single subsystems. The default can be left out and stays at info
+func ParseLevels(spec string) (Level, map[string]Level, error) {
+	def := Info
+	subsystems := make(map[string]Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := "", part
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], part[i+1:]
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return Info, nil, err
+		}
+		if name == "" {
+			def = level
+		} else {
+			subsystems[name] = level
+		}
+	}
+	return def, subsystems, nil
+}
+
+// Formats accepted by SetFormat
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatNone = "none"
 )
 
 // FileLogger - singleton
 type FileLogger struct {
-	Logger *log.Logger
 	file   *os.File
+	sink   Sink
+	level  Level
+	levels map[string]Level // per subsystem levels, overriding level
+	turn   int
+}
+
+// SubLogger - Logger for one subsystem like move, convert or spawn
+type SubLogger struct {
+	fl   *FileLogger
+	name string
 }
 
 var defaultLogger *FileLogger
+var logDir = "."
+var logLevel = Info
+var logLevels = map[string]Level{}
+var logFormat = FormatText
+
+// SetDir - Sets the directory the log file is created in. Call before NewFileLogger
+func SetDir(dir string) {
+	logDir = dir
+}
+
+// SetLevel - Sets the lowest level that gets logged. Call before NewFileLogger, Off skips creating the file
+// unless a subsystem still logs
+func SetLevel(level Level) {
+	logLevel = level
+	if defaultLogger != nil {
+		defaultLogger.level = level
+	}
+}
+
+// SetSubsystemLevel - Sets the lowest level logged for one subsystem, overriding SetLevel
+func SetSubsystemLevel(name string, level Level) {
+	logLevels[name] = level
+	if defaultLogger != nil {
+		defaultLogger.levels[name] = level
+	}
+}
+
+// SetFormat - Picks the format of the log file: text, json for JSON lines, or none to log nothing at all.
+// Call before NewFileLogger
+func SetFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatNone:
+		logFormat = format
+		return nil
+	}
+	return fmt.Errorf("log: unknown format %q, expected %s, %s or %s", format, FormatText, FormatJSON, FormatNone)
+}
 
 // GetInstance - Gets the logger instance
 func GetInstance() *FileLogger {
@@ -22,14 +137,30 @@ func GetInstance() *FileLogger {
 // NewFileLogger - Initializes the logger
 func NewFileLogger(botID int) *FileLogger {
 	if defaultLogger == nil {
-		var logfileName = fmt.Sprintf("bot-%d.log", botID)
+		levels := make(map[string]Level, len(logLevels))
+		for k, v := range logLevels {
+			levels[k] = v
+		}
+		defaultLogger = &FileLogger{sink: NopSink{}, level: logLevel, levels: levels}
+		if logFormat == FormatNone || !defaultLogger.anyEnabled() {
+			return defaultLogger
+		}
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			log.Fatal(err)
+		}
+		var logfileName = filepath.Join(logDir, fmt.Sprintf("bot-%d.log", botID))
+		if logFormat == FormatJSON {
+			logfileName = filepath.Join(logDir, fmt.Sprintf("bot-%d.jsonl", botID))
+		}
 		f, err := os.OpenFile(logfileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		var logger = log.New(f, "halite", log.Lshortfile|log.LstdFlags)
-		defaultLogger = &FileLogger{logger, f}
+		defaultLogger.file = f
+		defaultLogger.sink = NewTextSink(f)
+		if logFormat == FormatJSON {
+			defaultLogger.sink = NewJSONSink(f)
+		}
 	} else {
 		defaultLogger.Printf("Error: log: tried to open( %d ) but we have already opened before.", botID)
 	}
@@ -38,10 +169,110 @@ func NewFileLogger(botID int) *FileLogger {
 
 // Close -
 func (fl *FileLogger) Close() {
-	fl.file.Close()
+	if fl != nil && fl.file != nil {
+		fl.file.Close()
+	}
+}
+
+// SetTurn - Sets the turn stamped on every line from now on
+func (fl *FileLogger) SetTurn(turn int) {
+	if fl != nil {
+		fl.turn = turn
+	}
+}
+
+// Sub - Returns the logger for a subsystem. Safe to call before the logger exists, the lines are dropped
+func (fl *FileLogger) Sub(name string) *SubLogger {
+	return &SubLogger{fl, name}
+}
+
+// Enabled - Checks if lines of the level would be written for the subsystem
+func (fl *FileLogger) Enabled(subsystem string, level Level) bool {
+	if fl == nil || level >= Off {
+		return false
+	}
+	if _, nop := fl.sink.(NopSink); nop {
+		return false
+	}
+	if l, ok := fl.levels[subsystem]; ok {
+		return level >= l
+	}
+	return level >= fl.level
+}
+
+func (fl *FileLogger) anyEnabled() bool {
+	if fl.level < Off {
+		return true
+	}
+	for _, l := range fl.levels {
+		if l < Off {
+			return true
+		}
+	}
+	return false
 }
 
-// Printf - Passthrough to log.Logger
+// Printf - Free text line for the bot subsystem, logged at Info level
 func (fl *FileLogger) Printf(format string, v ...interface{}) {
-	fl.Logger.Printf(format, v...)
+	if fl.Enabled("bot", Info) {
+		fl.write("bot", Info, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+// Debugf - Free text line for the bot subsystem, logged at Debug level
+func (fl *FileLogger) Debugf(format string, v ...interface{}) {
+	if fl.Enabled("bot", Debug) {
+		fl.write("bot", Debug, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+// Warnf - Free text line for the bot subsystem, logged at Warn level
+func (fl *FileLogger) Warnf(format string, v ...interface{}) {
+	if fl.Enabled("bot", Warn) {
+		fl.write("bot", Warn, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+// Errorf - Free text line for the bot subsystem, logged at Error level
+func (fl *FileLogger) Errorf(format string, v ...interface{}) {
+	if fl.Enabled("bot", Error) {
+		fl.write("bot", Error, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+func (fl *FileLogger) write(subsystem string, level Level, msg string, fields Fields) {
+	if !fl.Enabled(subsystem, level) {
+		return
+	}
+	// skip write and the exported wrapper so the caller is the line that logged
+	caller := "???"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	fl.sink.Write(&Entry{time.Now(), level, subsystem, fl.turn, caller, strings.TrimRight(msg, "\n"), fields})
+}
+
+// Enabled - Checks if lines of the level would be written. Check before building expensive fields
+func (sl *SubLogger) Enabled(level Level) bool {
+	return sl.fl.Enabled(sl.name, level)
+}
+
+// Debug - Logs a message with fields at Debug level
+func (sl *SubLogger) Debug(msg string, fields Fields) {
+	sl.fl.write(sl.name, Debug, msg, fields)
+}
+
+// Info - Logs a message with fields at Info level
+func (sl *SubLogger) Info(msg string, fields Fields) {
+	sl.fl.write(sl.name, Info, msg, fields)
+}
+
+// Warn - Logs a message with fields at Warn level
+func (sl *SubLogger) Warn(msg string, fields Fields) {
+	sl.fl.write(sl.name, Warn, msg, fields)
+}
+
+// Error - Logs a message with fields at Error level
+func (sl *SubLogger) Error(msg string, fields Fields) {
+	sl.fl.write(sl.name, Error, msg, fields)
 }
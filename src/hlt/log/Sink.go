@@ -0,0 +1,98 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fields - Structured values attached to a log line, like ship, state, target and reason
+type Fields map[string]interface{}
+
+// Entry - One log line before it is formatted
+type Entry struct {
+	Time      time.Time
+	Level     Level
+	Subsystem string
+	Turn      int
+	Caller    string
+	Message   string
+	Fields    Fields
+}
+
+// Sink - Where log entries end up
+type Sink interface {
+	Write(e *Entry)
+}
+
+// NewTextSink - Sink writing the free text lines the bot always wrote, with the fields appended as key=value
+func NewTextSink(w io.Writer) Sink {
+	return &textSink{log.New(w, "halite", log.LstdFlags)}
+}
+
+// NewJSONSink - Sink writing one JSON object per line so logs can be queried
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{json.NewEncoder(w)}
+}
+
+// NopSink - Sink that drops everything. Loggers using it report every level as disabled, so callers
+// checking Enabled skip building the line at all
+type NopSink struct{}
+
+// Write - Drops the entry
+func (NopSink) Write(e *Entry) {}
+
+// fieldValue - Stringers like positions and states are logged by their String, nil pointers as nil
+func fieldValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	if st, ok := v.(fmt.Stringer); ok {
+		return st.String()
+	}
+	return v
+}
+
+type textSink struct {
+	logger *log.Logger
+}
+
+func (s *textSink) Write(e *Entry) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: [%s] turn %d %s: %s", e.Caller, e.Level, e.Turn, e.Subsystem, e.Message)
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, fieldValue(e.Fields[k]))
+	}
+	s.logger.Print(b.String())
+}
+
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+func (s *jsonSink) Write(e *Entry) {
+	line := make(map[string]interface{}, len(e.Fields)+6)
+	for k, v := range e.Fields {
+		line[k] = fieldValue(v)
+	}
+	line["time"] = e.Time.Format(time.RFC3339Nano)
+	line["level"] = e.Level.String()
+	line["sub"] = e.Subsystem
+	line["turn"] = e.Turn
+	line["caller"] = e.Caller
+	line["msg"] = e.Message
+	s.enc.Encode(line)
+}
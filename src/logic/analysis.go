@@ -0,0 +1,212 @@
+package logic
+
+import (
+	"hlt"
+	"sort"
+	"time"
+)
+
+// Cluster - Rich area of the map, centred on a local maximum of the halite density
+type Cluster struct {
+	Center  *hlt.Position
+	Density float64
+}
+
+// MapAnalysis - Board analysis done in the init window before the game starts. Kept on GameAI for the whole
+// game and only updated incrementally from the cells that changed since the last turn
+type MapAnalysis struct {
+	Window       int             // half size of the square window the density is averaged over
+	Density      [][]float64     // average halite in the window around each cell
+	YardDistance [][]int         // distance from our shipyard to each cell
+	Clusters     []*Cluster      // rich clusters, best first
+	Sites        []*hlt.Position // candidate dropoff sites, clusters far enough from the shipyard and each other
+	RichDensity  float64         // density a cell needs to count as rich, follows the map as it is mined out
+	Complete     bool            // false when the time limit cut the analysis short
+	richShare    float64         // share of the cells that count as rich
+	halite       [][]int         // halite seen last time the density was updated
+	gameMap      *hlt.GameMap
+}
+
+// Analyze - Builds the map analysis, stopping early once the deadline passes. The steps run cheapest and
+// most useful first so a cut short analysis still has the density field
+func Analyze(game *hlt.Game, params *Params, deadline time.Time) *MapAnalysis {
+	gMap := game.Map
+	w, h := gMap.Width(), gMap.Height()
+	window := w / 8
+	if window < 1 {
+		window = 1
+	}
+	ma := &MapAnalysis{
+		Window:       window,
+		Density:      make([][]float64, h),
+		YardDistance: make([][]int, h),
+		richShare:    params.AnalysisRichShare,
+		halite:       make([][]int, h),
+		gameMap:      gMap,
+	}
+	for y := 0; y < h; y++ {
+		ma.Density[y] = make([]float64, w)
+		ma.YardDistance[y] = make([]int, w)
+		ma.halite[y] = make([]int, w)
+		for x := 0; x < w; x++ {
+			ma.halite[y][x] = gMap.Cells[y][x].Halite
+		}
+	}
+	ma.buildDensity()
+	if time.Now().After(deadline) {
+		return ma
+	}
+	yard := game.Me.Shipyard.E.Pos
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ma.YardDistance[y][x] = toroidalDistance(x, yard.X(), w) + toroidalDistance(y, yard.Y(), h)
+		}
+	}
+	if time.Now().After(deadline) {
+		return ma
+	}
+	ma.findClusters(params.AnalysisMaxClusters)
+	if time.Now().After(deadline) {
+		return ma
+	}
+	ma.findSites(params.DropoffDistancePoint - params.DropoffDistanceThreshold)
+	ma.Complete = true
+	return ma
+}
+
+// Update - Applies the halite that changed since the last call to the density field and moves the rich threshold
+// along with it, so the richest parts of what is left still count as rich once the map depletes
+func (ma *MapAnalysis) Update() {
+	w, h := ma.gameMap.Width(), ma.gameMap.Height()
+	side := 2*ma.Window + 1
+	area := float64(side * side)
+	changed := false
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			now := ma.gameMap.Cells[y][x].Halite
+			delta := now - ma.halite[y][x]
+			if delta == 0 {
+				continue
+			}
+			changed = true
+			ma.halite[y][x] = now
+			change := float64(delta) / area
+			for dy := -ma.Window; dy <= ma.Window; dy++ {
+				row := ma.Density[((y+dy)%h+h)%h]
+				for dx := -ma.Window; dx <= ma.Window; dx++ {
+					row[((x+dx)%w+w)%w] += change
+				}
+			}
+		}
+	}
+	if changed {
+		ma.updateRichDensity()
+	}
+	for _, c := range ma.Clusters {
+		c.Density = ma.Density[c.Center.Y()][c.Center.X()]
+	}
+	sort.SliceStable(ma.Clusters, func(i, j int) bool {
+		return ma.Clusters[i].Density > ma.Clusters[j].Density
+	})
+}
+
+// DensityAt - Returns the halite density around the position
+func (ma *MapAnalysis) DensityAt(pos *hlt.Position) float64 {
+	return ma.Density[pos.Y()][pos.X()]
+}
+
+// IsRich - Checks if the position sits in one of the richer parts of the map
+func (ma *MapAnalysis) IsRich(pos *hlt.Position) bool {
+	return ma.DensityAt(pos) >= ma.RichDensity
+}
+
+// window sums through a summed area table over a map tiled three times so the torus wraps for free
+func (ma *MapAnalysis) buildDensity() {
+	w, h := ma.gameMap.Width(), ma.gameMap.Height()
+	sum := make([][]int, 3*h+1)
+	for y := range sum {
+		sum[y] = make([]int, 3*w+1)
+	}
+	for y := 1; y <= 3*h; y++ {
+		for x := 1; x <= 3*w; x++ {
+			sum[y][x] = ma.halite[(y-1)%h][(x-1)%w] + sum[y-1][x] + sum[y][x-1] - sum[y-1][x-1]
+		}
+	}
+	side := 2*ma.Window + 1
+	area := float64(side * side)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			top, left := y+h-ma.Window, x+w-ma.Window
+			bottom, right := top+side, left+side
+			total := sum[bottom][right] - sum[top][right] - sum[bottom][left] + sum[top][left]
+			ma.Density[y][x] = float64(total) / area
+		}
+	}
+	ma.updateRichDensity()
+}
+
+// the rich threshold is the density of the cell at the rich share from the top
+func (ma *MapAnalysis) updateRichDensity() {
+	values := make([]float64, 0, ma.gameMap.Width()*ma.gameMap.Height())
+	for _, row := range ma.Density {
+		values = append(values, row...)
+	}
+	sort.Float64s(values)
+	ma.RichDensity = values[int(float64(len(values)-1)*(1-ma.richShare))]
+}
+
+// clusters are rich cells that are the densest within their window
+func (ma *MapAnalysis) findClusters(maxClusters int) {
+	w, h := ma.gameMap.Width(), ma.gameMap.Height()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := ma.Density[y][x]
+			if d < ma.RichDensity || !ma.isPeak(x, y) {
+				continue
+			}
+			ma.Clusters = append(ma.Clusters, &Cluster{hlt.NewPosition(x, y), d})
+		}
+	}
+	sort.SliceStable(ma.Clusters, func(i, j int) bool {
+		return ma.Clusters[i].Density > ma.Clusters[j].Density
+	})
+	if len(ma.Clusters) > maxClusters {
+		ma.Clusters = ma.Clusters[:maxClusters]
+	}
+}
+
+func (ma *MapAnalysis) isPeak(x, y int) bool {
+	w, h := ma.gameMap.Width(), ma.gameMap.Height()
+	d := ma.Density[y][x]
+	for dy := -ma.Window; dy <= ma.Window; dy++ {
+		for dx := -ma.Window; dx <= ma.Window; dx++ {
+			o := ma.Density[((y+dy)%h+h)%h][((x+dx)%w+w)%w]
+			// ties go to the first cell in reading order so a flat plateau only yields one peak
+			if o > d || (o == d && (dy < 0 || (dy == 0 && dx < 0))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// candidate dropoff sites are the clusters, best first, at least the planner's spacing from the shipyard and from
+// each other
+func (ma *MapAnalysis) findSites(spacing int) {
+	w, h := ma.gameMap.Width(), ma.gameMap.Height()
+	for _, c := range ma.Clusters {
+		if ma.YardDistance[c.Center.Y()][c.Center.X()] < spacing {
+			continue
+		}
+		ok := true
+		for _, s := range ma.Sites {
+			if toroidalDistance(s.X(), c.Center.X(), w)+toroidalDistance(s.Y(), c.Center.Y(), h) < spacing {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			ma.Sites = append(ma.Sites, c.Center)
+		}
+	}
+}
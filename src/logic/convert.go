@@ -2,16 +2,7 @@ package logic
 
 import (
 	"hlt"
-	"hlt/gameconfig"
-	"math"
-)
-
-const (
-	dropoffDistancePoint = 13
-)
-
-var (
-	dropoffDistanceThreshold = 2
+	"hlt/log"
 )
 
 // ConvertAI - Object to handle conversion of ships to drop offs
@@ -27,32 +18,32 @@ func NewConvertAI(g *GameAI) *ConvertAI {
 	}
 }
 
-// DeterminePossibleDropOff - Determines whether a ship can become a drop off or not
+// DeterminePossibleDropOff - Converts the ship the dropoff planner sent out once it reaches the site and we can pay for it
 func (ca *ConvertAI) DeterminePossibleDropOff(ships map[int]*hlt.Ship) hlt.Command {
-	dropCost, _ := ca.game.config.GetInt(gameconfig.DropoffCost)
-	maxTurn, _ := ca.game.config.GetInt(gameconfig.MaxTurns)
-	// if we do not have enough halite or we are getting too close to the end of the match, don't convert
-	if ca.game.game.Me.Halite < (dropCost*(2*len(ca.game.dropOffs))) || (maxTurn-ca.game.game.TurnNumber) <= 230 {
+	planner := ca.game.dropoffPlanner
+	if planner.Target == nil {
+		return nil
+	}
+	ship, ok := ships[planner.Builder]
+	if !ok || !ship.E.Pos.Equals(planner.Target) || ca.game.onDropOff(ship.E.Pos) {
 		return nil
 	}
-	var optShip *hlt.Ship
-	optDis := 0
-	for i := range ships {
-		var s = ships[i]
-		if d, ok := ca.correctDistance(optDis, s.E.Pos, ca.game.dropOffs); ok {
-			optShip = s
-			optDis = d
-		}
+	// the ship cargo and the halite under it are credited against the dropoff cost
+	cost := planner.ConversionCost(ship)
+	if ca.game.tracer != nil {
+		ca.game.tracer.dropoff().Cost = cost
 	}
-	if optShip != nil && int(math.Abs(float64(dropoffDistancePoint-optDis))) <= dropoffDistanceThreshold {
-		if ca.game.onDropOff(optShip.E.Pos) {
-			return nil
-		}
-		ca.CurrentDropoff = optShip
-		ca.game.dropOffs = append(ca.game.dropOffs, optShip.E.Pos)
-		return optShip.MakeDropoff()
+	if !ca.game.ledger.SpendReserved(dropoffReservation, cost) {
+		return nil
+	}
+	if ca.game.tracer != nil {
+		ca.game.tracer.dropoff().Converted = true
 	}
-	return nil
+	log.GetInstance().Sub("convert").Info("converting", log.Fields{"ship": ship.E.ID(), "target": ship.E.Pos, "cargo": ship.Halite})
+	ca.CurrentDropoff = ship
+	ca.game.dropOffs = append(ca.game.dropOffs, ship.E.Pos)
+	planner.clear()
+	return ship.MakeDropoff()
 }
 
 // IsCurrentDropoff - Checks to see if the ship is the converted ship for this turn
@@ -62,23 +53,3 @@ func (ca *ConvertAI) IsCurrentDropoff(s *hlt.Ship) bool {
 	}
 	return false
 }
-
-func (ca *ConvertAI) correctDistance(optDis int, shipPos *hlt.Position, dos []*hlt.Position) (int, bool) {
-	var curPos *hlt.Position
-	curDis := optDis
-	for _, d := range dos {
-		tmpDis := ca.game.game.Map.CalculateDistance(shipPos, d)
-		// can be any distance greater than threshold. But cannot be too near any other docking station
-		// had ideas to maybe have some of these values fluctuate but never got around to playing with that
-		if (dropoffDistancePoint - tmpDis) > dropoffDistanceThreshold {
-			return 0, false
-		} else if curDis == 0 || tmpDis < curDis {
-			curDis = tmpDis
-			curPos = d
-		}
-	}
-	if curPos != nil {
-		return curDis, true
-	}
-	return 0, false
-}
@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"time"
+)
+
+// Deadline - Time budget for one turn, started as soon as the frame is read. Planners check it and return the
+// best result they have so far once it runs low. A nil Deadline never runs out
+type Deadline struct {
+	Turn  int
+	start time.Time
+	soft  time.Duration // past this planners stop looking for better answers
+	hard  time.Duration // past this only the cheapest fallbacks run
+}
+
+// NewDeadline - Starts the clock for a turn
+func NewDeadline(turn int, soft, hard time.Duration) *Deadline {
+	return &Deadline{
+		Turn:  turn,
+		start: time.Now(),
+		soft:  soft,
+		hard:  hard,
+	}
+}
+
+// Elapsed - Time spent on the turn so far
+func (d *Deadline) Elapsed() time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Since(d.start)
+}
+
+// Remaining - Time left before the hard budget runs out
+func (d *Deadline) Remaining() time.Duration {
+	if d == nil {
+		return time.Duration(1<<63 - 1)
+	}
+	return d.hard - d.Elapsed()
+}
+
+// OverSoft - Checks if the turn ran past the soft budget and planners should settle for what they have
+func (d *Deadline) OverSoft() bool {
+	return d != nil && d.Elapsed() >= d.soft
+}
+
+// Expired - Checks if the turn ran past the hard budget
+func (d *Deadline) Expired() bool {
+	return d != nil && d.Elapsed() >= d.hard
+}
+
+// Soft - Returns the soft budget, which never runs out for a nil Deadline
+func (d *Deadline) Soft() time.Duration {
+	if d == nil {
+		return time.Duration(1<<63 - 1)
+	}
+	return d.soft
+}
@@ -0,0 +1,245 @@
+package logic
+
+import (
+	"hlt"
+	"hlt/gameconfig"
+	"hlt/log"
+	"math"
+)
+
+const (
+	dropoffReservation = "dropoff" // ledger reservation held while a builder is on its way
+)
+
+// DropoffPlanner - Scores dropoff sites across the map and dispatches a ship to build on the best one
+type DropoffPlanner struct {
+	game      *GameAI
+	Target    *hlt.Position
+	Builder   int // ID of the ship sent to the target, -1 when nobody is assigned
+	score     float64
+	nextCheck int
+}
+
+// NewDropoffPlanner - Generates a new DropoffPlanner object
+func NewDropoffPlanner(g *GameAI) *DropoffPlanner {
+	return &DropoffPlanner{
+		game:    g,
+		Builder: -1,
+	}
+}
+
+// IsBuilder - Checks to see if the ship has been sent to build the next dropoff
+func (dp *DropoffPlanner) IsBuilder(ship *hlt.Ship) bool {
+	return dp.Target != nil && dp.Builder == ship.E.ID()
+}
+
+// ConversionCost - Returns what converting the ship costs us once its cargo and the halite under it are credited
+func (dp *DropoffPlanner) ConversionCost(ship *hlt.Ship) int {
+	dropCost, _ := dp.game.config.GetInt(gameconfig.DropoffCost)
+	cell := dp.game.game.Map.AtEntity(ship.E)
+	cost := dropCost - ship.Halite - cell.Halite
+	if cost < 0 {
+		return 0
+	}
+	return cost
+}
+
+// update - Drop the current plan if it went stale and look for a new site every so often.
+// Scanning the map for a site waits for a turn with time to spare
+func (dp *DropoffPlanner) update(deadline *Deadline) {
+	g := dp.game.game
+	if dp.Target != nil && !dp.stillValid() {
+		dp.clear()
+	}
+	if dp.Target == nil && g.TurnNumber >= dp.nextCheck && !deadline.OverSoft() {
+		dp.nextCheck = g.TurnNumber + dp.game.params.DropoffReplanTurns
+		dp.pickSite()
+	}
+	if dp.Target != nil {
+		if _, ok := g.Me.Ships[dp.Builder]; !ok {
+			dp.Builder = dp.closestShip(dp.Target)
+		}
+		dp.reserve()
+	}
+}
+
+// reserve - hold back what the conversion will cost once the builder arrives with its cargo
+func (dp *DropoffPlanner) reserve() {
+	g := dp.game.game
+	ship := g.Me.Ships[dp.Builder]
+	dropCost, _ := dp.game.config.GetInt(gameconfig.DropoffCost)
+	cost := dropCost - ship.Halite - g.Map.AtPosition(dp.Target).Halite
+	turns := g.Map.CalculateDistance(ship.E.Pos, dp.Target) + 1
+	dp.game.ledger.Reserve(dropoffReservation, cost, dp.Target, turns)
+}
+
+func (dp *DropoffPlanner) clear() {
+	dp.Target = nil
+	dp.Builder = -1
+	dp.score = 0
+	dp.game.ledger.Release(dropoffReservation)
+}
+
+func (dp *DropoffPlanner) stillValid() bool {
+	if len(dp.game.game.Me.Ships) == 0 || dp.minSpacing() > dp.ownDistance(dp.Target) {
+		return false
+	}
+	dropCost, _ := dp.game.config.GetInt(gameconfig.DropoffCost)
+	// keep the site as long as it is still reasonably rich, so the builder does not flip between sites
+	params := dp.game.params
+	return dp.scoreSite(dp.Target) >= float64(dropCost)*params.DropoffValueRatio*params.DropoffKeepRatio
+}
+
+func (dp *DropoffPlanner) pickSite() {
+	g := dp.game.game
+	dropCost, _ := dp.game.config.GetInt(gameconfig.DropoffCost)
+	if len(g.Me.Ships) < dp.game.params.DropoffShipsPerDock*len(dp.game.dropOffs) {
+		return
+	}
+	var best *hlt.Position
+	bestScore := float64(dropCost) * dp.game.params.DropoffValueRatio
+	for _, pos := range dp.candidates() {
+		if score := dp.scoreSite(pos); score > bestScore {
+			best = pos
+			bestScore = score
+		}
+	}
+	if best == nil {
+		return
+	}
+	dp.Target = best
+	dp.score = bestScore
+	dp.Builder = dp.closestShip(best)
+	log.GetInstance().Sub("convert").Info("planned dropoff site", log.Fields{"target": best, "score": math.Round(bestScore), "ship": dp.Builder})
+}
+
+// candidates - the sites the map analysis picked out of the clusters, or every rich cell when the analysis was
+// cut short before finding them, every cell without an analysis
+func (dp *DropoffPlanner) candidates() []*hlt.Position {
+	a := dp.game.analysis
+	if a != nil && a.Complete {
+		return a.Sites
+	}
+	var cells []*hlt.Position
+	for _, row := range dp.game.game.Map.Cells {
+		for _, cell := range row {
+			if a == nil || a.IsRich(cell.Pos) {
+				cells = append(cells, cell.Pos)
+			}
+		}
+	}
+	return cells
+}
+
+// scoreSite - Halite around the site, scaled down when it is close to the enemy or in their territory, or when too few
+// turns are left to use it
+func (dp *DropoffPlanner) scoreSite(pos *hlt.Position) float64 {
+	g := dp.game.game
+	maxTurn, _ := dp.game.config.GetInt(gameconfig.MaxTurns)
+	params := dp.game.params
+	spacing := dp.minSpacing()
+	own := dp.ownDistance(pos)
+	if own < spacing || g.Map.AtPosition(pos).HasStructure() {
+		return 0
+	}
+	// the site has to be reachable with enough turns left for it to pay off
+	_, travel := dp.closestShipDistance(pos)
+	left := maxTurn - g.TurnNumber - travel
+	if left < params.DropoffMinTurnsLeft {
+		return 0
+	}
+	timeFactor := math.Min(float64(left)/float64(2*params.DropoffMinTurnsLeft), 1.0)
+	enemyFactor := math.Min(float64(dp.enemyDistance(pos))/float64(spacing), 1.0)
+	// sites further out save our ships more travel, up to twice the spacing
+	spreadFactor := math.Min(float64(own)/float64(2*spacing), 1.0)
+	halite := 0.0
+	w, h := g.Map.Width(), g.Map.Height()
+	radius := params.DropoffSiteRadius
+	for dy := -radius; dy <= radius; dy++ {
+		span := radius - abs(dy)
+		y := ((pos.Y()+dy)%h + h) % h
+		for dx := -span; dx <= span; dx++ {
+			x := ((pos.X()+dx)%w + w) % w
+			d := abs(dx) + abs(dy)
+			halite += float64(g.Map.Cells[y][x].Halite) * (1.0 - float64(d)/float64(radius+1))
+		}
+	}
+	return halite * timeFactor * enemyFactor * dp.territoryFactor(pos) * (0.5 + 0.5*spreadFactor)
+}
+
+// territoryFactor - sites an opponent gets to first would mostly feed their ships. With more than one opponent
+// around, contested sites are only half safe too, which leaves the corners we hold
+func (dp *DropoffPlanner) territoryFactor(pos *hlt.Position) float64 {
+	im := dp.game.influence
+	if im == nil {
+		return 1
+	}
+	ratio := dp.game.params.InfluenceSiteRatio
+	switch {
+	case im.Enemy(pos):
+		return ratio
+	case im.Contested(pos) && dp.game.game.NumPlayers() > 2:
+		return (1 + ratio) / 2
+	}
+	return 1
+}
+
+// minSpacing - Closest a new dropoff is allowed to be to one of ours
+func (dp *DropoffPlanner) minSpacing() int {
+	return dp.game.params.DropoffDistancePoint - dp.game.params.DropoffDistanceThreshold
+}
+
+// ownDistance - distance to our closest dock, the shipyard's is looked up in the analysis when there is one
+func (dp *DropoffPlanner) ownDistance(pos *hlt.Position) int {
+	a := dp.game.analysis
+	if a == nil || !a.Complete {
+		_, d := dp.game.closestDropoff(pos)
+		return d
+	}
+	g := dp.game.game
+	best := a.YardDistance[pos.Y()][pos.X()]
+	for _, d := range dp.game.dropOffs {
+		if dis := g.Map.CalculateDistance(pos, d); dis < best {
+			best = dis
+		}
+	}
+	return best
+}
+
+func (dp *DropoffPlanner) enemyDistance(pos *hlt.Position) int {
+	g := dp.game.game
+	best := g.Map.Width() + g.Map.Height()
+	for _, p := range g.Players() {
+		if p.ID == g.Me.ID {
+			continue
+		}
+		if d := g.Map.CalculateDistance(pos, p.Shipyard.E.Pos); d < best {
+			best = d
+		}
+		for _, do := range p.Dropoffs {
+			if d := g.Map.CalculateDistance(pos, do.E.Pos); d < best {
+				best = d
+			}
+		}
+	}
+	return best
+}
+
+func (dp *DropoffPlanner) closestShip(pos *hlt.Position) int {
+	id, _ := dp.closestShipDistance(pos)
+	return id
+}
+
+// closestShipDistance - best placed ship for a site. Ties go to the ship carrying the most, since its cargo is credited on conversion
+func (dp *DropoffPlanner) closestShipDistance(pos *hlt.Position) (int, int) {
+	g := dp.game.game
+	bestID, bestDis, bestCargo := -1, 0, 0
+	for id, s := range g.Me.Ships {
+		d := g.Map.CalculateDistance(s.E.Pos, pos)
+		better := d < bestDis || (d == bestDis && (s.Halite > bestCargo || (s.Halite == bestCargo && id < bestID)))
+		if bestID == -1 || better {
+			bestID, bestDis, bestCargo = id, d, s.Halite
+		}
+	}
+	return bestID, bestDis
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+)
+
+// fieldBuilders - per cell values the bot works from, for heatmaps. Indexed [y][x]
+var fieldBuilders = map[string]func(gm *GameAI) [][]float64{
+	"halite": func(gm *GameAI) [][]float64 {
+		return gm.cellField(func(x, y int) float64 {
+			return float64(gm.game.Map.Cells[y][x].Halite)
+		})
+	},
+	"density": func(gm *GameAI) [][]float64 {
+		if gm.analysis == nil {
+			return nil
+		}
+		return gm.analysis.Density
+	},
+	"threat": func(gm *GameAI) [][]float64 {
+		if gm.threat == nil {
+			return nil
+		}
+		return gm.cellField(func(x, y int) float64 {
+			return float64(gm.threat.Threat(gm.game.Map.Cells[y][x].Pos))
+		})
+	},
+	"contested": func(gm *GameAI) [][]float64 {
+		if gm.threat == nil {
+			return nil
+		}
+		return gm.cellField(func(x, y int) float64 {
+			return gm.threat.Contested(gm.game.Map.Cells[y][x].Pos)
+		})
+	},
+	"territory": func(gm *GameAI) [][]float64 {
+		if gm.influence == nil {
+			return nil
+		}
+		return gm.cellField(func(x, y int) float64 {
+			return float64(gm.influence.Lead(gm.game.Map.Cells[y][x].Pos))
+		})
+	},
+	"distance": func(gm *GameAI) [][]float64 {
+		return gm.cellField(func(x, y int) float64 {
+			_, d := gm.closestDropoff(gm.game.Map.Cells[y][x].Pos)
+			return float64(d)
+		})
+	},
+	"dropoff_score": func(gm *GameAI) [][]float64 {
+		return gm.cellField(func(x, y int) float64 {
+			return gm.dropoffPlanner.scoreSite(gm.game.Map.Cells[y][x].Pos)
+		})
+	},
+}
+
+// FieldNames - Returns the names accepted by Field, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(fieldBuilders))
+	for name := range fieldBuilders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Field - Returns a per cell value the bot works from, like the halite density, threat map, distance to the
+// closest dock or dropoff site score. Indexed [y][x], nil when the field is not available yet
+func (gm *GameAI) Field(name string) ([][]float64, error) {
+	build, ok := fieldBuilders[name]
+	if !ok {
+		return nil, fmt.Errorf("logic: unknown field %q, expected one of %v", name, FieldNames())
+	}
+	return build(gm), nil
+}
+
+func (gm *GameAI) cellField(value func(x, y int) float64) [][]float64 {
+	w, h := gm.game.Map.Width(), gm.game.Map.Height()
+	field := make([][]float64, h)
+	for y := range field {
+		field[y] = make([]float64, w)
+		for x := range field[y] {
+			field[y][x] = value(x, y)
+		}
+	}
+	return field
+}
@@ -1,9 +1,12 @@
 package logic
 
 import (
+	"fmt"
+	"helper"
 	"hlt"
 	"hlt/gameconfig"
 	"math"
+	"time"
 )
 
 // ShipDecision - Representation of what logic the ship should perform
@@ -14,110 +17,339 @@ const (
 	Collect = ShipDecision(iota)
 	// Return - Return to nearest drop off
 	Return
-	// Convert - Convert ship into new drop off point
+	// Convert - Convert ship into new drop off point, ConvertAI issues these through the ledger
 	Convert
 	// Stay - The ship needs to stay where it is
 	Stay
+	// Build - Head to the planned dropoff site
+	Build
+	// Flee - Move away from an enemy ship that could ram us
+	Flee
+	// Attack - Ram the targeted enemy ship
+	Attack
 )
 
+var shipDecisionNames = [...]string{"Collect", "Return", "Convert", "Stay", "Build", "Flee", "Attack"}
+
+func (d ShipDecision) String() string {
+	if int(d) < len(shipDecisionNames) {
+		return shipDecisionNames[d]
+	}
+	return "Unknown"
+}
+
 // GameAI - Object to store/handle overall game logic
 type GameAI struct {
-	game                 *hlt.Game
-	config               *gameconfig.Constants
-	shipsMarkedForReturn map[int]bool    // keep track of ships returning to a dock
-	dropOffs             []*hlt.Position // keep track of drop offs
+	game           *hlt.Game
+	config         *gameconfig.Constants
+	params         *Params
+	machines       map[int]*shipMachine // per ship state machines, keyed by ship ID
+	dropOffs       []*hlt.Position      // keep track of drop offs
+	inspiration    *InspirationMap      // rebuilt every turn in Update
+	threat         *ThreatMap           // rebuilt every turn in Update
+	predictor      *MovePredictor       // enemy move predictions, learned over the game
+	influence      *InfluenceMap        // rebuilt every turn in Update
+	income         *incomeTracker       // observed halite income per ship
+	haliteLeft     int                  // halite remaining on the map this turn
+	dropoffPlanner *DropoffPlanner      // picks the next dropoff site and its builder
+	ledger         *Ledger              // halite budget every spending command goes through
+	analysis       *MapAnalysis         // board analysis from the init window, nil until Analyze runs
+	deadline       *Deadline            // time budget of the current turn
+	tracer         *Tracer              // decision trace, nil unless tracing
 }
 
 // NewGameAI - Generate a new GameAI object
-func NewGameAI(g *hlt.Game, c *gameconfig.Constants) *GameAI {
+func NewGameAI(g *hlt.Game, c *gameconfig.Constants, p *Params) *GameAI {
 	dos := make([]*hlt.Position, 0)
 	dos = append(dos, g.Me.Shipyard.E.Pos)
-	return &GameAI{
-		game:                 g,
-		config:               c,
-		shipsMarkedForReturn: make(map[int]bool),
-		dropOffs:             dos,
+	gm := &GameAI{
+		game:     g,
+		config:   c,
+		params:   p,
+		machines: make(map[int]*shipMachine),
+		dropOffs: dos,
+		income:   newIncomeTracker(g),
+		ledger:   NewLedger(),
+	}
+	gm.predictor = NewMovePredictor(g, c, p)
+	gm.dropoffPlanner = NewDropoffPlanner(gm)
+	return gm
+}
+
+// Analyze - Runs the pre-game map analysis, giving up on the slower steps once the deadline passes
+func (gm *GameAI) Analyze(deadline time.Time) *MapAnalysis {
+	gm.analysis = Analyze(gm.game, gm.params, deadline)
+	return gm.analysis
+}
+
+// Analysis - Returns the map analysis, nil if it was never run
+func (gm *GameAI) Analysis() *MapAnalysis {
+	return gm.analysis
+}
+
+// NewDeadline - Starts the time budget for the current turn from the params
+func (gm *GameAI) NewDeadline() *Deadline {
+	return NewDeadline(gm.game.TurnNumber,
+		time.Duration(gm.params.TurnSoftMillis)*time.Millisecond,
+		time.Duration(gm.params.TurnHardMillis)*time.Millisecond)
+}
+
+// Update - Refresh the per turn state. Should be called right after the frame is updated, planners
+// run against the deadline for the rest of the turn
+func (gm *GameAI) Update(deadline *Deadline) {
+	gm.deadline = deadline
+	// the engine is the source of truth for our docks, this also drops conversions it rejected
+	gm.dropOffs = []*hlt.Position{gm.game.Me.Shipyard.E.Pos}
+	for _, d := range gm.game.Me.Dropoffs {
+		gm.dropOffs = append(gm.dropOffs, d.E.Pos)
+	}
+	gm.ledger.Begin(gm.game.TurnNumber, gm.game.Me.Halite)
+	gm.inspiration = NewInspirationMap(gm.game, gm.config)
+	gm.predictor.Update()
+	if gm.params.PredictMoves != 0 {
+		gm.threat = NewThreatMap(gm.game, gm.predictor)
+	} else {
+		gm.threat = NewThreatMap(gm.game, nil)
+	}
+	gm.influence = NewInfluenceMap(gm.game, gm.params.InfluenceContestMargin)
+	for id := range gm.machines {
+		if _, ok := gm.game.Me.Ships[id]; !ok {
+			delete(gm.machines, id)
+		}
+	}
+	gm.income.update(gm.game, gm.config, gm.params.IncomeSmoothing)
+	if gm.analysis != nil {
+		gm.analysis.Update()
+	}
+	gm.haliteLeft = 0
+	for _, row := range gm.game.Map.Cells {
+		for _, c := range row {
+			gm.haliteLeft += c.Halite
+		}
+	}
+	gm.dropoffPlanner.update(deadline)
+	if gm.tracer != nil {
+		gm.tracer.Begin(gm.game.TurnNumber, gm.game.Me.Halite)
+		if dp := gm.dropoffPlanner; dp.Target != nil {
+			dt := gm.tracer.dropoff()
+			dt.Target, dt.Builder, dt.Score = pointOf(dp.Target), dp.Builder, dp.score
+			if r := gm.ledger.Reservation(dropoffReservation); r != nil {
+				dt.Reserved = r.Amount
+			}
+		}
+	}
+}
+
+// PlanTurn - Plans the whole turn the way the bot plays it: the conversion, a move for every other ship in ID order,
+// so ships moved first claim their cells and a fixed seed gives the same game, and the spawn. Whatever the planners
+// got wrong is held still or dropped by Validate rather than rejected by the engine. The planners are passed in so
+// the caller can look at the cells they claimed. Call it after Update
+func (gm *GameAI) PlanTurn(move *MoveAI, convert *ConvertAI) []hlt.Command {
+	var commands []hlt.Command
+	if com := convert.DeterminePossibleDropOff(gm.game.Me.Ships); com != nil {
+		commands = append(commands, com)
+	}
+	for _, ship := range helper.SortedShips(gm.game.Me.Ships) {
+		if convert.IsCurrentDropoff(ship) {
+			continue
+		}
+		commands = append(commands, move.Move(ship))
 	}
+	if com := NewSpawnAI(gm).Spawn(); com != nil {
+		commands = append(commands, com)
+	}
+	commands, _ = gm.Validate(commands)
+	return commands
+}
+
+// SetTracer - Starts recording every decision to the tracer, nil stops it
+func (gm *GameAI) SetTracer(t *Tracer) {
+	gm.tracer = t
+}
+
+// Tracer - Returns the decision tracer, nil when not tracing
+func (gm *GameAI) Tracer() *Tracer {
+	return gm.tracer
 }
 
-// ShipLogic - Figure out what decision the ship should make next
+// Params - Returns the strategy parameters the bot plays with
+func (gm *GameAI) Params() *Params {
+	return gm.params
+}
+
+// Ledger - Returns the halite budget for the current turn
+func (gm *GameAI) Ledger() *Ledger {
+	return gm.ledger
+}
+
+// DropoffPlanner - Returns the planner in charge of new dropoff sites
+func (gm *GameAI) DropoffPlanner() *DropoffPlanner {
+	return gm.dropoffPlanner
+}
+
+// Inspiration - Returns the inspiration map for the current turn
+func (gm *GameAI) Inspiration() *InspirationMap {
+	return gm.inspiration
+}
+
+// ShipLogic - Runs the ship's state machine for this turn and returns the decision it leads to
 func (gm *GameAI) ShipLogic(ship *hlt.Ship) ShipDecision {
+	decision, reason := gm.shipLogic(ship)
+	gm.machine(ship).reason = reason
+	return decision
+}
+
+// ShipReason - Returns why the ship made the decision it made this turn
+func (gm *GameAI) ShipReason(ship *hlt.Ship) string {
+	return gm.machine(ship).reason
+}
+
+func (gm *GameAI) shipLogic(ship *hlt.Ship) (ShipDecision, string) {
+	m := gm.machine(ship)
+	turn := gm.game.TurnNumber
 	currentCell := gm.game.Map.AtEntity(ship.E)
 	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
 	moveCost, _ := gm.config.GetDouble(gameconfig.MoveCostRatio)
-	dropCost, _ := gm.config.GetInt(gameconfig.DropoffCost)
 	maxTurn, _ := gm.config.GetInt(gameconfig.MaxTurns)
-	// If we have enough halite check if we should convert to a drop off
-	if gm.game.Me.Halite > (dropCost * 2) {
-		// this should never be true because we never let the ship get full
-		// plus the convert logic was pulled out into a ConvertAI object
-		if gm.shouldConvert(ship) {
-			gm.dropOffs = append(gm.dropOffs, ship.E.Pos)
-			return Convert
-		}
-	}
+	cargo := float64(ship.Halite) / float64(maxHalite)
 	// check our distance compared to how long it will take to get back to decide if we should return to a dock
-	if _, d := gm.closestDropoff(ship.E.Pos); (d + 3) >= (maxTurn - gm.game.TurnNumber) {
-		return Return
+	if dock, d := gm.closestDropoff(ship.E.Pos); (d + gm.params.EndgameMargin) >= (maxTurn - turn) {
+		m.transition(turn, EndgameReturn, "not enough turns left to head out again")
+		m.setTarget(EndgameReturn, dock)
+	}
+	if m.state == EndgameReturn {
+		if gm.onDropOff(ship.E.Pos) {
+			return Stay, "home for the end of the game"
+		}
+		return Return, "not enough turns left to head out again"
 	}
 	// if there is not enough halite to move and we are not on a drop off stay put
 	if math.Ceil(float64(currentCell.Halite)*(1.0/moveCost)) > float64(ship.Halite) && !gm.onDropOff(ship.E.Pos) {
-		return Stay
-	}
-	// check if ship is marked for return
-	if t, ok := gm.shipsMarkedForReturn[ship.E.ID()]; ok && t {
-		// if the ship has lost too much halite before hitting dock, forget about returning to dock
-		// this logic masked the problem with the method hasShipReturned
-		if (float64(ship.Halite)/float64(maxHalite)) < 0.4 && (maxTurn-gm.game.TurnNumber) > 50 {
-			delete(gm.shipsMarkedForReturn, ship.E.ID())
-			if currentCell.Halite < 10 {
-				return Collect
-			}
-			return Stay
-		}
-		// if the ship has returned to a drop off it needs to move on
-		// hasShipReturned implementation only checks for starting dock and not all docks
-		// but the logic to create extra docks made it to where this rarely occurred, but still a bug
-		if gm.hasShipReturned(currentCell, ship) {
-			return Collect
+		return Stay, "not enough cargo to pay for the move"
+	}
+	// the builder heads for the planned site and waits there until we can pay for the conversion
+	if gm.dropoffPlanner.IsBuilder(ship) {
+		m.transition(turn, Building, "sent to build the next drop off")
+		m.setTarget(Building, gm.dropoffPlanner.Target)
+		return Build, "sent to build the next drop off"
+	}
+	if m.state == Building {
+		m.transition(turn, Exploring, "no longer the drop off builder")
+	}
+	if enemy := gm.rammingThreat(ship); enemy != nil {
+		reason := fmt.Sprintf("enemy ship %d can ram our cargo", enemy.E.ID())
+		m.transition(turn, Fleeing, reason)
+		m.setTarget(Fleeing, enemy.E.Pos)
+		return Flee, reason
+	}
+	if enemy := gm.rammingTarget(ship); enemy != nil {
+		reason := fmt.Sprintf("enemy ship %d is carrying %d", enemy.E.ID(), enemy.Halite)
+		m.transition(turn, Attacking, reason)
+		m.setTarget(Attacking, enemy.E.Pos)
+		return Attack, reason
+	}
+	if m.state == Fleeing || m.state == Attacking {
+		m.transition(turn, m.resumeState(), "no enemy in reach any more")
+	}
+	if m.state == Returning {
+		// dropping off at any of our docks counts as having returned
+		if gm.onDropOff(ship.E.Pos) {
+			m.transition(turn, Exploring, "dropped off cargo")
+			m.tripStart = turn
+		} else if cargo < gm.params.ReturnAbandonRatio && (maxTurn-turn) > gm.params.ReturnAbandonTurns {
+			// if the ship has lost too much halite before hitting dock, forget about returning to dock
+			m.transition(turn, Mining, "lost too much cargo on the way back")
+		} else {
+			dock, _ := gm.closestDropoff(ship.E.Pos)
+			m.setTarget(Returning, dock)
+			return Return, "carrying cargo back"
 		}
-		return Return
 	}
-	// if the ship was full it wouldn't move
-	if (float64(ship.Halite) / float64(maxHalite)) > 0.9 {
-		gm.shipsMarkedForReturn[ship.E.ID()] = true
-		return Return
+	// weigh topping up the cargo nearby against the trip back
+	if ok, reason := gm.shouldReturn(ship, turn-m.tripStart); ok {
+		dock, _ := gm.closestDropoff(ship.E.Pos)
+		m.transition(turn, Returning, reason)
+		m.setTarget(Returning, dock)
+		return Return, reason
 	}
-	if currentCell.Halite < 10 {
-		return Collect
+	if currentCell.Halite < gm.params.MinMineHalite {
+		m.transition(turn, Exploring, "cell is mined out")
+		return Collect, "cell is mined out"
 	}
-	return Stay
+	m.transition(turn, Mining, "cell has halite")
+	m.setTarget(Mining, ship.E.Pos)
+	return Stay, "cell has halite"
 }
 
-func (gm *GameAI) onDropOff(pos *hlt.Position) bool {
-	for i := 0; i < len(gm.dropOffs); i++ {
-		if pos.Equals(gm.dropOffs[i]) {
-			return true
+// ShipState - Returns the state the ship's state machine is in
+func (gm *GameAI) ShipState(ship *hlt.Ship) ShipState {
+	return gm.machine(ship).state
+}
+
+// ShipHistory - Returns the recorded state transitions of the ship
+func (gm *GameAI) ShipHistory(ship *hlt.Ship) []Transition {
+	return gm.machine(ship).history
+}
+
+func (gm *GameAI) machine(ship *hlt.Ship) *shipMachine {
+	m, ok := gm.machines[ship.E.ID()]
+	if !ok {
+		m = newShipMachine(ship.E.ID(), gm.game.TurnNumber)
+		gm.machines[ship.E.ID()] = m
+	}
+	return m
+}
+
+// rammingThreat - an adjacent enemy carrying much less than us has little to lose by crashing into our cargo
+func (gm *GameAI) rammingThreat(ship *hlt.Ship) *hlt.Ship {
+	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
+	if float64(ship.Halite) < gm.params.FleeCargoRatio*float64(maxHalite) || gm.onDropOff(ship.E.Pos) {
+		return nil
+	}
+	for _, e := range gm.threat.EnemiesReaching(ship.E.Pos) {
+		if e.Halite < ship.Halite/2 && gm.threat.Chance(e, ship.E.Pos) >= gm.params.PredictMinRamChance {
+			return e
 		}
 	}
-	return false
+	return nil
 }
 
-func (gm *GameAI) hasShipReturned(currentCell *hlt.MapCell, ship *hlt.Ship) bool {
-	// this should actually be changed to check for all dock positions
-	if currentCell.Pos.Equals(gm.game.Me.Shipyard.E.Pos) {
-		gm.shipsMarkedForReturn[ship.E.ID()] = false
-		return true
+// rammingTarget - only worth trading an empty ship for a loaded one in small games, when the enemy is likely
+// to still be there, and only when we have a ship close by to pick up what gets dropped
+func (gm *GameAI) rammingTarget(ship *hlt.Ship) *hlt.Ship {
+	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
+	if gm.game.NumPlayers() > gm.params.AttackMaxPlayers || float64(ship.Halite) > gm.params.AttackCargoRatio*float64(maxHalite) {
+		return nil
 	}
-	return false
+	for _, e := range gm.threat.EnemiesReaching(ship.E.Pos) {
+		if e.Halite < gm.params.AttackMinEnemyCargo || gm.game.Map.AtEntity(e.E).HasStructure() || e.E.Pos.Equals(ship.E.Pos) ||
+			gm.threat.Chance(e, e.E.Pos) < gm.params.PredictMinRamChance {
+			continue
+		}
+		support := 0
+		for _, s := range gm.game.Me.Ships {
+			if s.E.ID() != ship.E.ID() && gm.game.Map.CalculateDistance(s.E.Pos, e.E.Pos) <= 2 {
+				support++
+			}
+		}
+		if support > 0 {
+			return e
+		}
+	}
+	return nil
+}
+
+// endgameRush - the last turns of the game, when returning ships head straight home and may crash on the dock
+func (gm *GameAI) endgameRush() bool {
+	maxTurns, _ := gm.config.GetInt(gameconfig.MaxTurns)
+	return maxTurns-gm.game.TurnNumber <= len(gm.game.Me.Ships)
 }
 
-// this should never return true because we have logic to not let a ship become full
-// we also have ConvertAI object to handle the logic for conversion
-func (gm *GameAI) shouldConvert(ship *hlt.Ship) bool {
-	// TODO set up logic to determine conversion
-	if ship.IsFull() {
-		return true
+func (gm *GameAI) onDropOff(pos *hlt.Position) bool {
+	for i := 0; i < len(gm.dropOffs); i++ {
+		if pos.Equals(gm.dropOffs[i]) {
+			return true
+		}
 	}
 	return false
 }
@@ -0,0 +1,214 @@
+package logic_test
+
+import (
+	"helper"
+	"hlt"
+	"logic"
+	"scenario"
+	"testing"
+)
+
+// board - a 32x32 two player board with the shipyards at (8, 16) and (23, 16)
+func board(turn int) *scenario.Scenario {
+	return scenario.New(32, 32).Turn(turn).Shipyard(0, 8, 16).Shipyard(1, 23, 16)
+}
+
+func world(t *testing.T, s *scenario.Scenario) *scenario.World {
+	t.Helper()
+	w, err := s.World(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestShipLogic(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s *scenario.Scenario) int
+		want  logic.ShipDecision
+	}{
+		{"full ship returns", func(s *scenario.Scenario) int {
+			return s.Ship(0, 10, 16, 990)
+		}, logic.Return},
+		{"empty ship mines a rich cell", func(s *scenario.Scenario) int {
+			s.Halite(10, 16, 800)
+			return s.Ship(0, 10, 16, 0)
+		}, logic.Stay},
+		{"empty ship leaves a mined out cell", func(s *scenario.Scenario) int {
+			s.Halite(10, 16, 5).Halite(11, 16, 600)
+			return s.Ship(0, 10, 16, 10)
+		}, logic.Collect},
+		{"ship that can't pay stays", func(s *scenario.Scenario) int {
+			s.Halite(12, 16, 900)
+			return s.Ship(0, 12, 16, 20)
+		}, logic.Stay},
+		{"loaded ship flees an empty enemy next to it", func(s *scenario.Scenario) int {
+			s.Ship(1, 13, 16, 0)
+			return s.Ship(0, 12, 16, 700)
+		}, logic.Flee},
+		{"loaded ship heads home past an enemy carrying as much", func(s *scenario.Scenario) int {
+			s.Ship(1, 13, 16, 700)
+			return s.Ship(0, 12, 16, 700)
+		}, logic.Return},
+	}
+	for _, tt := range tests {
+		s := board(50)
+		id := tt.setup(s)
+		w := world(t, s)
+		got, err := w.Decision(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: decision %v (%s), want %v", tt.name, got, w.AI.ShipReason(w.Ship(id)), tt.want)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	s := board(50)
+	full := s.Ship(0, 10, 16, 990)
+	w := world(t, s)
+	com, err := w.Move(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := w.Ship(full).Move(hlt.West()); com.CommandString() != want.CommandString() {
+		t.Errorf("full ship east of the shipyard: %q, want %q", com.CommandString(), want.CommandString())
+	}
+
+	// the first ship claims the cell west, the second one can't end the turn on it too
+	s = board(50)
+	first := s.Ship(0, 11, 16, 990)
+	second := s.Ship(0, 11, 15, 990)
+	w = world(t, s)
+	ends := make(map[string]int)
+	for _, id := range []int{first, second} {
+		com, err := w.Move(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		end := w.Ship(id).E.Pos
+		if m, ok := com.(*hlt.Move); ok {
+			end = helper.NormalizedDirectionalOffset(end, w.Game.Map, m.Direction())
+		}
+		ends[end.String()]++
+	}
+	for pos, n := range ends {
+		if n > 1 {
+			t.Errorf("%d ships end on %s", n, pos)
+		}
+	}
+}
+
+func TestFleeMove(t *testing.T) {
+	s := board(50)
+	enemy := s.Ship(1, 13, 16, 0)
+	ship := s.Ship(0, 12, 16, 700)
+	w := world(t, s)
+	com, err := w.Move(ship)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := com.(*hlt.Move)
+	if !ok {
+		t.Fatalf("fleeing ship: %q, want a move", com.CommandString())
+	}
+	end := helper.NormalizedDirectionalOffset(w.Ship(ship).E.Pos, w.Game.Map, m.Direction())
+	if d := w.Game.Map.CalculateDistance(end, w.Ship(enemy).E.Pos); d < 2 {
+		t.Errorf("fleeing ship ends %d from the enemy with %q", d, com.CommandString())
+	}
+}
+
+func TestDeterminePossibleDropOff(t *testing.T) {
+	// a rich patch far from the shipyard, and the 8 ships per dock the 32x32 profile wants before building another
+	build := func(site [2]int, bank int) (*scenario.World, int) {
+		s := board(50).Bank(0, bank)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				if dx, dy := x-8, y-3; abs(dx)+abs(dy) <= 6 {
+					s.Halite(x, y, 600)
+				}
+			}
+		}
+		builder := s.Ship(0, site[0], site[1], 0)
+		for i := 0; i < 8; i++ {
+			s.Ship(0, 4+i, 18, 0)
+		}
+		return world(t, s), builder
+	}
+
+	w, _ := build([2]int{8, 5}, 6000)
+	planner := w.AI.DropoffPlanner()
+	if planner.Target == nil {
+		t.Fatal("no dropoff site planned next to the rich patch")
+	}
+	if d := w.Game.Map.CalculateDistance(planner.Target, hlt.NewPosition(8, 3)); d > 2 {
+		t.Errorf("dropoff site %s is %d from the middle of the rich patch", planner.Target, d)
+	}
+	if com := w.Dropoff(); com != nil {
+		t.Errorf("builder converted before reaching the site: %q", com.CommandString())
+	}
+
+	site := [2]int{planner.Target.X(), planner.Target.Y()}
+	w, builder := build(site, 6000)
+	com := w.Dropoff()
+	if want := w.Ship(builder).MakeDropoff(); com == nil || com.CommandString() != want.CommandString() {
+		t.Errorf("builder on the site: %v, want %q", com, want.CommandString())
+	}
+
+	// the cell and the cargo only cover part of the cost, the rest has to be in the bank
+	w, _ = build(site, 1000)
+	if com := w.Dropoff(); com != nil {
+		t.Errorf("converted without the halite to pay for it: %q", com.CommandString())
+	}
+}
+
+func TestSpawnDeferredByReservation(t *testing.T) {
+	s := board(5).Fill(300).Bank(0, 1500)
+	w := world(t, s)
+	if com := logic.NewSpawnAI(w.AI).Spawn(); com == nil {
+		t.Fatal("no spawn on a rich board with the halite for it")
+	}
+
+	w = world(t, s)
+	w.AI.Ledger().Reserve("dropoff", 1000, nil, 5)
+	if com := logic.NewSpawnAI(w.AI).Spawn(); com != nil {
+		t.Errorf("spawned with the halite held for a dropoff: %q", com.CommandString())
+	}
+	if spent := w.AI.Ledger().Spent(); spent != 0 {
+		t.Errorf("deferred spawn spent %d", spent)
+	}
+}
+
+func TestInspiration(t *testing.T) {
+	tests := []struct {
+		name    string
+		enemies [][2]int
+		want    bool
+	}{
+		{"no enemies", nil, false},
+		{"one enemy", [][2]int{{16, 7}}, false},
+		{"two enemies in reach", [][2]int{{16, 7}, {18, 5}}, true},
+		{"second enemy out of reach", [][2]int{{16, 7}, {21, 5}}, false},
+	}
+	for _, tt := range tests {
+		s := board(50)
+		ship := s.Ship(0, 16, 5, 0)
+		for _, e := range tt.enemies {
+			s.Ship(1, e[0], e[1], 0)
+		}
+		w := world(t, s)
+		if got := w.AI.Inspiration().IsInspired(w.Ship(ship)); got != tt.want {
+			t.Errorf("%s: inspired %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,157 @@
+package logic
+
+import (
+	"helper"
+	"hlt"
+)
+
+// InfluenceMap - Per turn view of which player gets to each cell first from its ships and docks, and by how many
+// turns. Cells two players reach within the contest margin of each other belong to nobody
+type InfluenceMap struct {
+	gameMap  *hlt.GameMap
+	me       int
+	players  []int     // player IDs, lined up with distance
+	distance [][][]int // distance[i][y][x] - turns the closest ship or dock of players[i] needs to reach the cell
+	owner    [][]int   // owner[y][x] - player ID reaching the cell first, -1 when contested
+}
+
+// NewInfluenceMap - Builds the influence map for the current turn, cells reached within margin turns of another
+// player count as contested
+func NewInfluenceMap(game *hlt.Game, margin int) *InfluenceMap {
+	h, w := game.Map.Height(), game.Map.Width()
+	im := &InfluenceMap{
+		gameMap: game.Map,
+		me:      game.Me.ID,
+		owner:   make([][]int, h),
+	}
+	for _, p := range game.Players() {
+		sources := []*hlt.Position{p.Shipyard.E.Pos}
+		for _, d := range p.Dropoffs {
+			sources = append(sources, d.E.Pos)
+		}
+		for _, s := range helper.SortedShips(p.Ships) {
+			sources = append(sources, s.E.Pos)
+		}
+		im.players = append(im.players, p.ID)
+		im.distance = append(im.distance, distanceField(game.Map, sources))
+	}
+	for y := 0; y < h; y++ {
+		im.owner[y] = make([]int, w)
+		for x := 0; x < w; x++ {
+			first, best, second := -1, w+h, w+h
+			for i, dist := range im.distance {
+				switch d := dist[y][x]; {
+				case d < best:
+					first, best, second = i, d, best
+				case d < second:
+					second = d
+				}
+			}
+			im.owner[y][x] = -1
+			if first >= 0 && second-best > margin {
+				im.owner[y][x] = im.players[first]
+			}
+		}
+	}
+	return im
+}
+
+// distanceField - multi-source breadth first search over the wrapping map, turns from the closest source to every cell
+func distanceField(gameMap *hlt.GameMap, sources []*hlt.Position) [][]int {
+	h, w := gameMap.Height(), gameMap.Width()
+	dist := make([][]int, h)
+	for y := range dist {
+		dist[y] = make([]int, w)
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	queue := make([]int, 0, w*h)
+	for _, s := range sources {
+		if dist[s.Y()][s.X()] < 0 {
+			dist[s.Y()][s.X()] = 0
+			queue = append(queue, s.Y()*w+s.X())
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		x, y := queue[i]%w, queue[i]/w
+		next := [4][2]int{{x, (y + h - 1) % h}, {x, (y + 1) % h}, {(x + 1) % w, y}, {(x + w - 1) % w, y}}
+		for _, n := range next {
+			if dist[n[1]][n[0]] < 0 {
+				dist[n[1]][n[0]] = dist[y][x] + 1
+				queue = append(queue, n[1]*w+n[0])
+			}
+		}
+	}
+	// a player without ships or docks reaches nothing
+	for y := range dist {
+		for x := range dist[y] {
+			if dist[y][x] < 0 {
+				dist[y][x] = w + h
+			}
+		}
+	}
+	return dist
+}
+
+// Owner - Returns the ID of the player reaching the position first, -1 when it is contested
+func (im *InfluenceMap) Owner(pos *hlt.Position) int {
+	return im.owner[pos.Y()][pos.X()]
+}
+
+// Contested - Checks whether another player reaches the position within the contest margin of the first one
+func (im *InfluenceMap) Contested(pos *hlt.Position) bool {
+	return im.owner[pos.Y()][pos.X()] == -1
+}
+
+// Ours - Checks whether we reach the position first by more than the contest margin
+func (im *InfluenceMap) Ours(pos *hlt.Position) bool {
+	return im.owner[pos.Y()][pos.X()] == im.me
+}
+
+// Enemy - Checks whether an opponent reaches the position first by more than the contest margin
+func (im *InfluenceMap) Enemy(pos *hlt.Position) bool {
+	o := im.owner[pos.Y()][pos.X()]
+	return o != -1 && o != im.me
+}
+
+// Distance - Returns the turns the player's closest ship or dock needs to reach the position, -1 for unknown players
+func (im *InfluenceMap) Distance(player int, pos *hlt.Position) int {
+	for i, id := range im.players {
+		if id == player {
+			return im.distance[i][pos.Y()][pos.X()]
+		}
+	}
+	return -1
+}
+
+// Lead - Returns how many turns sooner we reach the position than the closest opponent, negative when they are
+// there first. Cells deep inside our territory, like the safe corners of a 4 player game, have the largest lead
+func (im *InfluenceMap) Lead(pos *hlt.Position) int {
+	ours, theirs := 0, -1
+	for i, id := range im.players {
+		d := im.distance[i][pos.Y()][pos.X()]
+		if id == im.me {
+			ours = d
+		} else if theirs < 0 || d < theirs {
+			theirs = d
+		}
+	}
+	if theirs < 0 {
+		return im.gameMap.Width() + im.gameMap.Height()
+	}
+	return theirs - ours
+}
+
+// Share - Returns the share of the map the player reaches first by more than the contest margin
+func (im *InfluenceMap) Share(player int) float64 {
+	cells := 0
+	for _, row := range im.owner {
+		for _, o := range row {
+			if o == player {
+				cells++
+			}
+		}
+	}
+	return float64(cells) / float64(im.gameMap.Width()*im.gameMap.Height())
+}
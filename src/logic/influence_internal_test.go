@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"hlt"
+	"testing"
+)
+
+func TestDistanceField(t *testing.T) {
+	gameMap := hlt.NewGameMap(5, 4)
+	dist := distanceField(gameMap, []*hlt.Position{hlt.NewPosition(0, 0), hlt.NewPosition(0, 0)})
+	// the shortest way to every cell wraps past the edges for the far half of the board
+	want := [][]int{
+		{0, 1, 2, 2, 1},
+		{1, 2, 3, 3, 2},
+		{2, 3, 4, 4, 3},
+		{1, 2, 3, 3, 2},
+	}
+	for y := range want {
+		for x := range want[y] {
+			if dist[y][x] != want[y][x] {
+				t.Errorf("distance to %d,%d is %d, want %d", x, y, dist[y][x], want[y][x])
+			}
+		}
+	}
+	// a player with no ships or docks reaches nothing
+	for y, row := range distanceField(gameMap, nil) {
+		for x, d := range row {
+			if d != 5+4 {
+				t.Errorf("no sources: distance to %d,%d is %d, want 9", x, y, d)
+			}
+		}
+	}
+}
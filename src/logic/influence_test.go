@@ -0,0 +1,52 @@
+package logic_test
+
+import (
+	"hlt"
+	"logic"
+	"scenario"
+	"testing"
+)
+
+// a 12x8 board with our shipyard at (0, 0) and a ship at (0, 6), their shipyard at (6, 4) and a contest margin of 1
+func TestInfluenceMap(t *testing.T) {
+	s := scenario.New(12, 8).Shipyard(0, 0, 0).Shipyard(1, 6, 4)
+	s.Ship(0, 0, 6, 0)
+	game, err := s.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := logic.NewInfluenceMap(game, 1)
+	tests := []struct {
+		name        string
+		x, y        int
+		ours, their int
+		owner, lead int
+	}{
+		// one step west and one north of our shipyard, over both edges
+		{"wrapped corner", 11, 7, 2, 8, 0, 6},
+		{"next to our ship", 0, 5, 1, 7, 0, 6},
+		{"their shipyard", 6, 4, 8, 0, 1, -8},
+		{"tie", 3, 2, 5, 5, -1, 0},
+		{"two turns ahead", 2, 2, 4, 6, 0, 2},
+		{"two turns behind", 4, 3, 7, 3, 1, -4},
+	}
+	for _, tt := range tests {
+		pos := hlt.NewPosition(tt.x, tt.y)
+		if ours, their := im.Distance(0, pos), im.Distance(1, pos); ours != tt.ours || their != tt.their {
+			t.Errorf("%s: distances %d and %d, want %d and %d", tt.name, ours, their, tt.ours, tt.their)
+		}
+		if owner := im.Owner(pos); owner != tt.owner {
+			t.Errorf("%s: owner %d, want %d", tt.name, owner, tt.owner)
+		}
+		if lead := im.Lead(pos); lead != tt.lead {
+			t.Errorf("%s: lead %d, want %d", tt.name, lead, tt.lead)
+		}
+		if im.Contested(pos) != (tt.owner == -1) || im.Ours(pos) != (tt.owner == 0) || im.Enemy(pos) != (tt.owner == 1) {
+			t.Errorf("%s: contested %v, ours %v, enemy %v with owner %d", tt.name, im.Contested(pos), im.Ours(pos),
+				im.Enemy(pos), tt.owner)
+		}
+	}
+	if got := im.Distance(2, hlt.NewPosition(0, 0)); got != -1 {
+		t.Errorf("distance of a player not in the game %d, want -1", got)
+	}
+}
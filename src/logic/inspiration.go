@@ -0,0 +1,173 @@
+package logic
+
+import (
+	"hlt"
+	"hlt/gameconfig"
+	"math"
+)
+
+// InspirationMap - Per turn snapshot of which cells and ships are inspired
+type InspirationMap struct {
+	gameMap      *hlt.GameMap
+	me           int
+	enabled      bool
+	radius       int
+	shipCount    int
+	bonus        float64
+	counts       [][][]int // counts[player][y][x] - ships of player within radius of the cell
+	enemyEdge    [][]float64
+	edgeShips    []*hlt.Ship // enemy ships one ship short of being inspired
+	inspired     map[int]bool
+	inspiredByUs map[int]bool
+}
+
+// NewInspirationMap - Builds the inspiration map for the current turn
+func NewInspirationMap(game *hlt.Game, config *gameconfig.Constants) *InspirationMap {
+	enabled, _ := config.GetBool(gameconfig.InspirationEnabled)
+	radius, _ := config.GetInt(gameconfig.InspirationRadius)
+	shipCount, _ := config.GetInt(gameconfig.InspirationShipCount)
+	bonus, _ := config.GetDouble(gameconfig.InspiredBonusMultiplier)
+	width, height := game.Map.Width(), game.Map.Height()
+	players := game.Players()
+	im := &InspirationMap{
+		gameMap:      game.Map,
+		me:           game.Me.ID,
+		enabled:      enabled && shipCount > 0,
+		radius:       radius,
+		shipCount:    shipCount,
+		bonus:        bonus,
+		counts:       make([][][]int, len(players)),
+		enemyEdge:    make([][]float64, height),
+		inspired:     make(map[int]bool),
+		inspiredByUs: make(map[int]bool),
+	}
+	for y := range im.enemyEdge {
+		im.enemyEdge[y] = make([]float64, width)
+	}
+	if !im.enabled {
+		return im
+	}
+	for i, p := range players {
+		im.counts[i] = make([][]int, height)
+		for y := range im.counts[i] {
+			im.counts[i][y] = make([]int, width)
+		}
+		for _, s := range p.Ships {
+			im.forEachInRadius(s.E.Pos, func(x, y int) {
+				im.counts[i][y][x]++
+			})
+		}
+	}
+	for _, p := range players {
+		for _, s := range p.Ships {
+			near := im.opponentsNear(s.E.Pos, p.ID)
+			if near >= shipCount {
+				im.inspired[s.E.ID()] = true
+				if p.ID != im.me && near-im.count(im.me, s.E.Pos) < shipCount {
+					im.inspiredByUs[s.E.ID()] = true
+				}
+			} else if p.ID != im.me && near == shipCount-1 {
+				// one more of our ships in range would hand this ship the bonus
+				value := im.enemyBonus(s)
+				im.edgeShips = append(im.edgeShips, s)
+				im.forEachInRadius(s.E.Pos, func(x, y int) {
+					im.enemyEdge[y][x] += value
+				})
+			}
+		}
+	}
+	return im
+}
+
+// Enabled - Returns whether inspiration is active in this game
+func (im *InspirationMap) Enabled() bool {
+	return im.enabled
+}
+
+// WouldBeInspired - Checks if one of our ships sitting on pos would be inspired
+func (im *InspirationMap) WouldBeInspired(pos *hlt.Position) bool {
+	return im.enabled && im.opponentsNear(pos, im.me) >= im.shipCount
+}
+
+// IsInspired - Checks if the ship (ours or an enemy's) is currently inspired
+func (im *InspirationMap) IsInspired(ship *hlt.Ship) bool {
+	return im.inspired[ship.E.ID()]
+}
+
+// InspiredByUs - Checks if an enemy ship is inspired only because of our ships
+func (im *InspirationMap) InspiredByUs(ship *hlt.Ship) bool {
+	return im.inspiredByUs[ship.E.ID()]
+}
+
+// Value - Returns the halite value of a cell for our ships, boosted by the bonus multiplier when inspired
+func (im *InspirationMap) Value(cell *hlt.MapCell) float64 {
+	if im.WouldBeInspired(cell.Pos) {
+		return float64(cell.Halite) * (1.0 + im.bonus)
+	}
+	return float64(cell.Halite)
+}
+
+// EnemyBonusFrom - Estimates the bonus value handed to enemy ships if our ship moved from origin to pos.
+// Uses the same scale as Value so the two can be compared directly
+func (im *InspirationMap) EnemyBonusFrom(origin, pos *hlt.Position) float64 {
+	if !im.enabled {
+		return 0
+	}
+	cost := im.enemyEdge[pos.Y()][pos.X()]
+	if cost == 0 {
+		return 0
+	}
+	// enemies already within range of our ship are not affected by it moving
+	for _, s := range im.edgeShips {
+		if im.within(origin, s.E.Pos) && im.within(pos, s.E.Pos) {
+			cost -= im.enemyBonus(s)
+		}
+	}
+	return math.Max(cost, 0)
+}
+
+func (im *InspirationMap) enemyBonus(s *hlt.Ship) float64 {
+	cell := im.gameMap.AtEntity(s.E)
+	return float64(cell.Halite) * im.bonus
+}
+
+func (im *InspirationMap) count(player int, pos *hlt.Position) int {
+	if player < 0 || player >= len(im.counts) || im.counts[player] == nil {
+		return 0
+	}
+	return im.counts[player][pos.Y()][pos.X()]
+}
+
+func (im *InspirationMap) opponentsNear(pos *hlt.Position, player int) int {
+	total := 0
+	for i := range im.counts {
+		if i != player {
+			total += im.count(i, pos)
+		}
+	}
+	return total
+}
+
+func (im *InspirationMap) within(a, b *hlt.Position) bool {
+	return im.gameMap.CalculateDistance(a, b) <= im.radius
+}
+
+// walk the diamond of cells within the inspiration radius, wrapping around the map
+func (im *InspirationMap) forEachInRadius(pos *hlt.Position, fn func(x, y int)) {
+	width, height := im.gameMap.Width(), im.gameMap.Height()
+	for dy := -im.radius; dy <= im.radius; dy++ {
+		span := im.radius - abs(dy)
+		y := ((pos.Y()+dy)%height + height) % height
+		for dx := -span; dx <= span; dx++ {
+			x := ((pos.X()+dx)%width + width) % width
+			fn(x, y)
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,139 @@
+package logic
+
+import (
+	"fmt"
+	"hlt"
+	"hlt/log"
+	"sort"
+)
+
+// Reservation - Halite set aside for a purchase we expect to make within a few turns
+type Reservation struct {
+	Name     string
+	Amount   int
+	Pos      *hlt.Position // where the halite is going to be spent, nil if it does not matter
+	Deadline int           // last turn the reservation holds before it expires
+}
+
+// Ledger - Per turn budget of our banked halite. Every spending command has to go through it
+// so two commands never spend the same halite in one turn
+type Ledger struct {
+	turn         int
+	halite       int
+	spent        int
+	reservations map[string]*Reservation
+}
+
+// NewLedger - Generates a new Ledger object
+func NewLedger() *Ledger {
+	return &Ledger{
+		reservations: make(map[string]*Reservation),
+	}
+}
+
+// Begin - Starts a new turn with the halite we have banked and drops reservations that ran out of time
+func (l *Ledger) Begin(turn, halite int) {
+	l.turn = turn
+	l.halite = halite
+	l.spent = 0
+	for name, r := range l.reservations {
+		if r.Deadline < turn {
+			log.GetInstance().Sub("ledger").Info("reservation expired", log.Fields{"name": name, "amount": r.Amount, "deadline": r.Deadline})
+			delete(l.reservations, name)
+		}
+	}
+}
+
+// Reserve - Sets aside halite under a name for the next few turns, replacing any earlier reservation with that name
+func (l *Ledger) Reserve(name string, amount int, pos *hlt.Position, turns int) {
+	if amount <= 0 {
+		l.Release(name)
+		return
+	}
+	if _, ok := l.reservations[name]; !ok {
+		log.GetInstance().Sub("ledger").Info("reserving", log.Fields{"name": name, "amount": amount, "target": pos, "turns": turns})
+	}
+	l.reservations[name] = &Reservation{name, amount, pos, l.turn + turns}
+}
+
+// Release - Drops a reservation
+func (l *Ledger) Release(name string) {
+	delete(l.reservations, name)
+}
+
+// Reservation - Returns the reservation with the given name, nil if there is none
+func (l *Ledger) Reservation(name string) *Reservation {
+	return l.reservations[name]
+}
+
+// Reserved - Returns the total halite held by reservations
+func (l *Ledger) Reserved() int {
+	return l.reservedExcept("")
+}
+
+// Available - Returns the halite that can still be spent this turn without touching a reservation
+func (l *Ledger) Available() int {
+	return l.halite - l.spent - l.Reserved()
+}
+
+// Spend - Spends halite that is not reserved. Returns false if the purchase was refused or deferred
+func (l *Ledger) Spend(purpose string, amount int) bool {
+	return l.spend(purpose, "", amount)
+}
+
+// SpendReserved - Spends halite held by the named reservation, topping up from unreserved halite if needed.
+// The reservation is released once the purchase goes through
+func (l *Ledger) SpendReserved(name string, amount int) bool {
+	if !l.spend(name, name, amount) {
+		return false
+	}
+	l.Release(name)
+	return true
+}
+
+// Spent - Returns the halite spent so far this turn
+func (l *Ledger) Spent() int {
+	return l.spent
+}
+
+func (l *Ledger) spend(purpose, reservation string, amount int) bool {
+	logger := log.GetInstance().Sub("ledger")
+	free := l.halite - l.spent - l.reservedExcept(reservation)
+	if amount > free {
+		switch {
+		case !logger.Enabled(log.Info):
+		case amount <= l.halite-l.spent:
+			logger.Info("deferred", log.Fields{"purpose": purpose, "amount": amount, "left": l.halite - l.spent,
+				"reserved": l.reservedExcept(reservation), "reason": fmt.Sprintf("reserved for %v", l.names(reservation))})
+		default:
+			logger.Info("refused", log.Fields{"purpose": purpose, "amount": amount, "left": l.halite - l.spent})
+		}
+		return false
+	}
+	l.spent += amount
+	if logger.Enabled(log.Info) {
+		logger.Info("spent", log.Fields{"purpose": purpose, "amount": amount, "left": l.halite - l.spent})
+	}
+	return true
+}
+
+func (l *Ledger) reservedExcept(name string) int {
+	total := 0
+	for n, r := range l.reservations {
+		if n != name {
+			total += r.Amount
+		}
+	}
+	return total
+}
+
+func (l *Ledger) names(except string) []string {
+	names := make([]string, 0, len(l.reservations))
+	for n := range l.reservations {
+		if n != except {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
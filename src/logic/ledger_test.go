@@ -0,0 +1,39 @@
+package logic
+
+import "testing"
+
+func TestLedgerDefersToReservations(t *testing.T) {
+	l := NewLedger()
+	l.Begin(10, 5000)
+	l.Reserve("dropoff", 3500, nil, 3)
+	if got := l.Available(); got != 1500 {
+		t.Fatalf("Available = %d, want 1500", got)
+	}
+	if !l.Spend("spawn", 1000) {
+		t.Fatal("spawn refused with 1500 unreserved")
+	}
+	if l.Spend("spawn", 1000) {
+		t.Error("second spawn went through on halite held for the dropoff")
+	}
+	// the reservation tops up from what is left unreserved, and is gone once it is used
+	if !l.SpendReserved("dropoff", 4000) {
+		t.Fatal("dropoff refused with 4000 left")
+	}
+	if l.Reservation("dropoff") != nil || l.Spent() != 5000 || l.Available() != 0 {
+		t.Errorf("after the dropoff: reservation %v, spent %d, available %d", l.Reservation("dropoff"), l.Spent(), l.Available())
+	}
+}
+
+func TestLedgerReservationExpires(t *testing.T) {
+	l := NewLedger()
+	l.Begin(10, 2000)
+	l.Reserve("dropoff", 2000, nil, 2)
+	l.Begin(12, 2000)
+	if l.Spend("spawn", 1000) {
+		t.Error("spawn went through while the reservation still held")
+	}
+	l.Begin(13, 2000)
+	if l.Reservation("dropoff") != nil || !l.Spend("spawn", 1000) {
+		t.Error("reservation outlived its deadline")
+	}
+}
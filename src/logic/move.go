@@ -4,17 +4,19 @@ import (
 	"fmt"
 	"helper"
 	"hlt"
-	"hlt/gameconfig"
+	"hlt/log"
 	"math"
 	"math/rand"
 )
 
 // MoveAI - Object to store and compute logic for the game
 type MoveAI struct {
-	gameAI    *GameAI
-	FuturePos map[string]*hlt.MapCell
-	Map       *hlt.GameMap
-	Me        *hlt.Player
+	gameAI     *GameAI
+	FuturePos  map[string]*hlt.MapCell
+	Map        *hlt.GameMap
+	Me         *hlt.Player
+	path       []*hlt.Position // route planned for the ship being moved
+	candidates []Candidate     // cells scored for the ship being moved, only collected when tracing
 }
 
 // NewMoveAI - Generates a new MoveAI object to use
@@ -29,14 +31,44 @@ func NewMoveAI(gm *GameAI, gMap *hlt.GameMap, p *hlt.Player) *MoveAI {
 
 // Move - Returns the command on how the ship should or should not move
 func (move *MoveAI) Move(ship *hlt.Ship) hlt.Command {
-	switch move.gameAI.ShipLogic(ship) {
+	if move.gameAI.deadline.Expired() {
+		// out of time, staying still is the one move that needs no planning
+		move.MarkFuturePos(ship.E.Pos)
+		return ship.StayStill()
+	}
+	move.path, move.candidates = nil, nil
+	decision := move.gameAI.ShipLogic(ship)
+	com := move.decide(ship, decision)
+	if tracer := move.gameAI.tracer; tracer != nil {
+		m := move.gameAI.machine(ship)
+		st := tracer.ship(ship)
+		st.State, st.Decision, st.Reason = m.state.String(), decision.String(), m.reason
+		st.Target, st.Command = pointOf(m.target(m.state)), com.CommandString()
+		for _, p := range move.path {
+			st.Path = append(st.Path, *pointOf(p))
+		}
+		tracer.candidates(ship, move.candidates)
+	}
+	if logger := log.GetInstance().Sub("move"); logger.Enabled(log.Debug) {
+		m := move.gameAI.machine(ship)
+		logger.Debug("decision", log.Fields{"ship": ship.E.ID(), "state": m.state, "decision": decision,
+			"target": m.target(m.state), "command": com.CommandString()})
+	}
+	return com
+}
+
+func (move *MoveAI) decide(ship *hlt.Ship, decision ShipDecision) hlt.Command {
+	switch decision {
 	case Collect:
 		return move.determinePath(ship)
 	case Return:
 		return move.navigateToDropOff(ship)
-	case Convert:
-		move.gameAI.dropOffs = append(move.gameAI.dropOffs, ship.E.Pos)
-		return ship.MakeDropoff()
+	case Build:
+		return move.navigateCheaply(ship, move.gameAI.dropoffPlanner.Target)
+	case Flee:
+		return move.flee(ship)
+	case Attack:
+		return move.attack(ship)
 	case Stay:
 		break
 	}
@@ -63,9 +95,20 @@ func (move *MoveAI) IsFutureClaimed(pos *hlt.Position) bool {
 }
 
 func (move *MoveAI) determinePath(ship *hlt.Ship) hlt.Command {
-	cell := move.findMostHaliteInWindow(ship.E.Pos, 4+int(math.Floor(float64(move.gameAI.game.TurnNumber)/100.0)))
-
-	return ship.Move(move.lazyGreedySearch(cell.Pos, ship.E.Pos, 4))
+	params := move.gameAI.params
+	window := params.SearchWindowBase + int(math.Floor(float64(move.gameAI.game.TurnNumber)/float64(params.SearchWindowTurns)))
+	cell := move.findMostHaliteInWindow(ship.E.Pos, window)
+	if cell == nil {
+		// nothing worth mining in reach, head for the closest rich cluster from the map analysis
+		if c := move.closestCluster(ship.E.Pos); c != nil {
+			move.gameAI.machine(ship).setTarget(Exploring, c.Center)
+			return ship.Move(move.lazyGreedySearch(c.Center, ship.E.Pos, params.SearchDepth))
+		}
+		move.MarkFuturePos(ship.E.Pos)
+		return ship.StayStill()
+	}
+	move.gameAI.machine(ship).setTarget(Exploring, cell.Pos)
+	return ship.Move(move.lazyGreedySearch(cell.Pos, ship.E.Pos, params.SearchDepth))
 	// if d, found := move.findDirectionToCell(cell, ship.E.Pos); found {
 	// 	return ship.Move(d)
 	// }
@@ -86,7 +129,6 @@ func (move *MoveAI) randomAvailableDirection(ship *hlt.Ship) hlt.Command {
 }
 
 func (move *MoveAI) navigateToDropOff(ship *hlt.Ship) hlt.Command {
-	maxTurns, _ := move.gameAI.config.GetInt(gameconfig.MaxTurns)
 	var dropoff *hlt.Position
 	dDis := 0
 	for _, d := range move.gameAI.dropOffs {
@@ -102,7 +144,7 @@ func (move *MoveAI) navigateToDropOff(ship *hlt.Ship) hlt.Command {
 	// if the number of turns is less than the amount of ships
 	// disregard saftey checks in lazyGreedySearch and just navigate to destination (should be dock)
 	// even if they need to crash into other ships
-	if (maxTurns - move.gameAI.game.TurnNumber) <= len(move.Me.Ships) {
+	if move.gameAI.endgameRush() {
 		dirs := move.Map.GetUnsafeMoves(ship.E.Pos, dropoff)
 		fDir := move.determineBestDirectionOutOfTwo(dirs, dropoff)
 		if fDir != nil {
@@ -110,7 +152,7 @@ func (move *MoveAI) navigateToDropOff(ship *hlt.Ship) hlt.Command {
 		}
 		return ship.StayStill()
 	}
-	return ship.Move(move.lazyGreedySearch(dropoff, ship.E.Pos, 4))
+	return ship.Move(move.lazyGreedySearch(dropoff, ship.E.Pos, move.gameAI.params.SearchDepth))
 	// dir := move.Map.NaiveNavigate(ship, dropoff)
 	// nextPos := helper.NormalizedDirectionalOffset(ship.E.Pos, move.Map, dir)
 	// if !move.IsFutureClaimed(nextPos) {
@@ -121,19 +163,81 @@ func (move *MoveAI) navigateToDropOff(ship *hlt.Ship) hlt.Command {
 	// return ship.StayStill()
 }
 
+func (move *MoveAI) closestCluster(pos *hlt.Position) *Cluster {
+	if move.gameAI.analysis == nil {
+		return nil
+	}
+	var best *Cluster
+	bestDis := 0
+	for _, c := range move.gameAI.analysis.Clusters {
+		d := move.Map.CalculateDistance(pos, c.Center)
+		if d > 0 && (best == nil || d < bestDis) {
+			best, bestDis = c, d
+		}
+	}
+	return best
+}
+
+// step onto the neighbouring cell the fewest enemies are expected to move onto, preferring cells closer to a dock
+func (move *MoveAI) flee(ship *hlt.Ship) hlt.Command {
+	dock, _ := move.gameAI.closestDropoff(ship.E.Pos)
+	best := hlt.Still()
+	bestPos := ship.E.Pos
+	bestThreat := move.gameAI.threat.Expected(ship.E.Pos)
+	bestDis := move.Map.CalculateDistance(ship.E.Pos, dock)
+	if move.IsFutureClaimed(ship.E.Pos) {
+		bestThreat = math.Inf(1)
+	}
+	for _, d := range move.AvailableDirectionsForPos(ship.E.Pos) {
+		pos := helper.NormalizedDirectionalOffset(ship.E.Pos, move.Map, d)
+		threat := move.gameAI.threat.Expected(pos)
+		dis := move.Map.CalculateDistance(pos, dock)
+		if threat < bestThreat || (threat == bestThreat && dis < bestDis) {
+			best, bestPos, bestThreat, bestDis = d, pos, threat, dis
+		}
+	}
+	move.MarkFuturePos(bestPos)
+	return ship.Move(best)
+}
+
+// move onto the targeted enemy ship unless one of our own ships already claimed the cell
+func (move *MoveAI) attack(ship *hlt.Ship) hlt.Command {
+	target := move.gameAI.machine(ship).target(Attacking)
+	if target != nil && !move.IsFutureClaimed(target) {
+		for _, d := range move.Map.GetUnsafeMoves(ship.E.Pos, target) {
+			if !d.Equals(hlt.Still()) {
+				move.MarkFuturePos(target)
+				return ship.Move(d)
+			}
+		}
+	}
+	return move.determinePath(ship)
+}
+
 // create grid and keep searching out to a certain depth for the cell with the most halite and that is close
 func (move *MoveAI) findMostHaliteInWindow(pos *hlt.Position, n int) *hlt.MapCell {
 	var answer *hlt.MapCell
+	answerValue := 0.0
 	for i := 0; i < n; i++ {
+		// rings go outwards, so short on time the best cell found so far is the best close by
+		if answer != nil && move.gameAI.deadline.OverSoft() {
+			break
+		}
 		panels := helper.NormalizedGridOutlineOffset(pos, move.Map, i+1)
 		for j := 0; j < len(panels); j++ {
 			cell := move.Map.AtPosition(panels[j])
-			if cell.Halite > 10 {
+			if cell.Halite > move.gameAI.params.MinMineHalite {
+				value := move.targetValue(pos, cell)
+				if move.gameAI.tracer != nil {
+					move.candidates = append(move.candidates, Candidate{*pointOf(cell.Pos), value})
+				}
 				if answer == nil {
 					answer = cell
-				} else if cell.Halite > answer.Halite {
+					answerValue = value
+				} else if value > answerValue {
 					answer = cell
-				} else if cell.Halite == answer.Halite {
+					answerValue = value
+				} else if value == answerValue {
 					cp := move.Map.CalculateDistance(pos, cell.Pos)
 					ap := move.Map.CalculateDistance(pos, answer.Pos)
 					if cp < ap {
@@ -146,6 +250,23 @@ func (move *MoveAI) findMostHaliteInWindow(pos *hlt.Position, n int) *hlt.MapCel
 	return answer
 }
 
+// value of mining a cell: inspired cells get the bonus multiplier and
+// we knock off whatever bonus our ship would hand to nearby enemy ships by going there,
+// and part of it when an enemy looks set to move onto the cell first or holds the territory around it
+func (move *MoveAI) targetValue(origin *hlt.Position, cell *hlt.MapCell) float64 {
+	value := float64(cell.Halite)
+	if insp := move.gameAI.inspiration; insp != nil {
+		value = insp.Value(cell) - insp.EnemyBonusFrom(origin, cell.Pos)
+	}
+	if tm := move.gameAI.threat; tm != nil {
+		value *= 1 - move.gameAI.params.PredictContestPenalty*tm.Contested(cell.Pos)
+	}
+	if im := move.gameAI.influence; im != nil && im.Enemy(cell.Pos) {
+		value *= move.gameAI.params.InfluenceMiningRatio
+	}
+	return value
+}
+
 // This method was replaced with the lazyGreedySearch
 func (move *MoveAI) findDirectionToCell(answer *hlt.MapCell, pos *hlt.Position) (*hlt.Direction, bool) {
 	if answer != nil {
@@ -239,11 +360,12 @@ func (move *MoveAI) lazyGreedySearch(target, src *hlt.Position, depth int) *hlt.
 		posDir := helper.NormalizedDirectionalOffset(src, move.Map, d)
 		if posDir.Equals(target) {
 			move.MarkFuturePos(posDir)
+			move.path = []*hlt.Position{posDir}
 			return d
 		}
 		history[d] = []*hlt.Position{posDir}
 	}
-	for i := 0; i < depth; i++ {
+	for i := 0; i < depth && !move.gameAI.deadline.OverSoft(); i++ {
 		for _, d := range parents {
 			his := history[d]
 			curSrc := his[len(his)-1]
@@ -254,6 +376,7 @@ func (move *MoveAI) lazyGreedySearch(target, src *hlt.Position, depth int) *hlt.
 				if _, ok := claimed[fmt.Sprintf("%s%s", d.String(), ad.String())]; !ok {
 					if ad.Equals(target) {
 						move.MarkFuturePos(his[0])
+						move.path = append(his, ad)
 						return d
 					}
 					tmpDis := move.Map.CalculateDistance(ad, target)
@@ -273,7 +396,9 @@ func (move *MoveAI) lazyGreedySearch(target, src *hlt.Position, depth int) *hlt.
 	var bestDir *hlt.Direction
 	bestDis := 100000000
 	var bestPos *hlt.Position
-	for k, v := range history {
+	// in the order of parents, ties going to the first direction keeps a seeded game repeatable
+	for _, k := range parents {
+		v := history[k]
 		if len(v) == 0 {
 			continue
 		}
@@ -287,6 +412,7 @@ func (move *MoveAI) lazyGreedySearch(target, src *hlt.Position, depth int) *hlt.
 	}
 	if bestDir != nil {
 		move.MarkFuturePos(bestPos)
+		move.path = history[bestDir]
 		return bestDir
 	}
 	return hlt.Still()
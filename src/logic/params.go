@@ -0,0 +1,381 @@
+package logic
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Params - Strategy tuning knobs. Every field can be set from a JSON params file using its json name
+// or from a command line flag of the same name. Values nobody set explicitly come from the profile for the
+// map size and player count, see ApplyProfile
+type Params struct {
+	// Mining
+	MinMineHalite     int `json:"min_mine_halite"`     // cells with less halite are not worth mining
+	SearchWindowBase  int `json:"search_window_base"`  // rings searched for the richest cell at the start of the game
+	SearchWindowTurns int `json:"search_window_turns"` // the search window grows by one ring every this many turns
+	SearchDepth       int `json:"search_depth"`        // depth of the lazy greedy path search
+	EndgameMargin     int `json:"endgame_margin"`      // spare turns kept when heading home for the end of the game
+
+	// Returning to a dock
+	ReturnLookahead     int     `json:"return_lookahead"`       // turns of extra mining weighed against heading back now
+	ReturnMinCargoRatio float64 `json:"return_min_cargo_ratio"` // never head back with less than this share of MaxHalite
+	ReturnAlwaysRatio   float64 `json:"return_always_ratio"`    // ships this full always head back
+	ReturnAbandonRatio  float64 `json:"return_abandon_ratio"`   // returning ships below this share of MaxHalite go back to mining
+	ReturnAbandonTurns  int     `json:"return_abandon_turns"`   // ... as long as more than this many turns are left
+	ReturnRamRisk       float64 `json:"return_ram_risk"`        // chance per turn of being rammed for every enemy expected to reach us
+	ReturnMaxRisk       float64 `json:"return_max_risk"`        // cap on the chance of losing the cargo
+	ReturnSearchRadius  int     `json:"return_search_radius"`   // cells around the ship considered for topping up
+	ReturnTripRiskShare float64 `json:"return_trip_risk_share"` // moving ships are harder to catch than ones sitting still
+
+	// Fleeing and ramming
+	FleeCargoRatio      float64 `json:"flee_cargo_ratio"`       // ships carrying more than this share of MaxHalite run from enemies
+	AttackCargoRatio    float64 `json:"attack_cargo_ratio"`     // ships carrying less than this share of MaxHalite may ram
+	AttackMinEnemyCargo int     `json:"attack_min_enemy_cargo"` // enemy cargo that makes ramming worth losing a ship
+	AttackMaxPlayers    int     `json:"attack_max_players"`     // only ram in games with at most this many players
+
+	// Predicting enemy moves
+	PredictMoves          int     `json:"predict_moves"`           // 1 weighs enemy ships by their predicted moves, 0 assumes any move
+	PredictPriorWeight    float64 `json:"predict_prior_weight"`    // observed moves before an opponent's own play counts as much as the prior
+	PredictFullRatio      float64 `json:"predict_full_ratio"`      // enemy ships carrying this share of MaxHalite are expected to head home
+	PredictMinRamChance   float64 `json:"predict_min_ram_chance"`  // enemies less likely to end on a ship's cell are no ramming threat or target
+	PredictContestPenalty float64 `json:"predict_contest_penalty"` // share of a mining target's value lost when an enemy is sure to move onto it
+
+	// Spawning
+	IncomeSmoothing    float64 `json:"income_smoothing"`     // weight of the newest turn in the income per ship average
+	HarvestableRatio   float64 `json:"harvestable_ratio"`    // share of the map halite we expect the fleets to ever pick up
+	PriorMiningShare   float64 `json:"prior_mining_share"`   // share of turns a fresh ship spends mining rather than travelling
+	WarmupIncomeTurns  int     `json:"warmup_income_turns"`  // turns of observed income before trusting it over the prior
+	SpawnProfitPadding float64 `json:"spawn_profit_padding"` // a new ship has to pay back this many times its cost
+
+	// Dropoffs
+	DropoffDistancePoint     int     `json:"dropoff_distance_point"`     // ideal distance between our docks
+	DropoffDistanceThreshold int     `json:"dropoff_distance_threshold"` // how much closer than the ideal distance a new dock may be
+	DropoffSiteRadius        int     `json:"dropoff_site_radius"`        // halite within this distance of a site counts towards it
+	DropoffMinTurnsLeft      int     `json:"dropoff_min_turns_left"`     // a dock needs this many turns after it is built to pay off
+	DropoffReplanTurns       int     `json:"dropoff_replan_turns"`       // how often to look for a new site while we have none
+	DropoffValueRatio        float64 `json:"dropoff_value_ratio"`        // nearby halite has to be worth this many dropoff costs
+	DropoffKeepRatio         float64 `json:"dropoff_keep_ratio"`         // share of the value ratio a planned site has to keep
+	DropoffShipsPerDock      int     `json:"dropoff_ships_per_dock"`     // ships we want per existing dock before building another
+
+	// Territory
+	InfluenceContestMargin int     `json:"influence_contest_margin"` // cells another player reaches within this many turns of the first are contested
+	InfluenceSiteRatio     float64 `json:"influence_site_ratio"`     // share of a dropoff site's score kept when an opponent holds it
+	InfluenceMiningRatio   float64 `json:"influence_mining_ratio"`   // share of a mining target's value kept when an opponent holds it
+
+	// Pre-game analysis
+	AnalysisMaxClusters int     `json:"analysis_max_clusters"` // rich clusters kept for the rest of the game
+	AnalysisRichShare   float64 `json:"analysis_rich_share"`   // cells in the top share of density count as rich
+	InitAnalysisMillis  int     `json:"init_analysis_millis"`  // time allowed for the analysis before Ready
+
+	// Turn timing, the engine allows 2000ms a turn
+	TurnSoftMillis int `json:"turn_soft_millis"` // planners settle for their best answer so far past this
+	TurnHardMillis int `json:"turn_hard_millis"` // ships not handled by this point stay still
+
+	Profiles []Profile       `json:"profiles,omitempty"` // per map size and player count values, merged over DefaultProfiles
+	explicit map[string]bool // parameters set from a file or flag, profiles leave these alone
+}
+
+// DefaultParams - Returns the parameters the bot plays with unless told otherwise
+func DefaultParams() *Params {
+	return &Params{
+		MinMineHalite:     10,
+		SearchWindowBase:  4,
+		SearchWindowTurns: 100,
+		SearchDepth:       4,
+		EndgameMargin:     3,
+
+		ReturnLookahead:     5,
+		ReturnMinCargoRatio: 0.5,
+		ReturnAlwaysRatio:   0.97,
+		ReturnAbandonRatio:  0.4,
+		ReturnAbandonTurns:  50,
+		ReturnRamRisk:       0.1,
+		ReturnMaxRisk:       0.9,
+		ReturnSearchRadius:  2,
+		ReturnTripRiskShare: 0.5,
+
+		FleeCargoRatio:      0.5,
+		AttackCargoRatio:    0.25,
+		AttackMinEnemyCargo: 500,
+		AttackMaxPlayers:    2,
+
+		PredictMoves:          1,
+		PredictPriorWeight:    20,
+		PredictFullRatio:      0.7,
+		PredictMinRamChance:   0.1,
+		PredictContestPenalty: 0.5,
+
+		IncomeSmoothing:    0.05,
+		HarvestableRatio:   0.8,
+		PriorMiningShare:   0.5,
+		WarmupIncomeTurns:  20,
+		SpawnProfitPadding: 1.1,
+
+		DropoffDistancePoint:     13,
+		DropoffDistanceThreshold: 2,
+		DropoffSiteRadius:        6,
+		DropoffMinTurnsLeft:      100,
+		DropoffReplanTurns:       10,
+		DropoffValueRatio:        2.0,
+		DropoffKeepRatio:         0.7,
+		DropoffShipsPerDock:      6,
+
+		InfluenceContestMargin: 1,
+		InfluenceSiteRatio:     0.5,
+		InfluenceMiningRatio:   0.8,
+
+		AnalysisMaxClusters: 12,
+		AnalysisRichShare:   0.25,
+		InitAnalysisMillis:  5000,
+
+		TurnSoftMillis: 1000,
+		TurnHardMillis: 1600,
+
+		Profiles: DefaultProfiles(),
+		explicit: make(map[string]bool),
+	}
+}
+
+// unbounded - open end of a parameter's bounds
+var unbounded = math.Inf(1)
+
+// paramBounds - smallest and largest value of every parameter. Counts and anything we divide by are at least 1,
+// shares and chances lie between 0 and 1
+var paramBounds = map[string][2]float64{
+	"min_mine_halite":     {0, 1000},
+	"search_window_base":  {0, unbounded},
+	"search_window_turns": {1, unbounded},
+	"search_depth":        {1, unbounded},
+	"endgame_margin":      {0, unbounded},
+
+	"return_lookahead":       {0, unbounded},
+	"return_min_cargo_ratio": {0, 1},
+	"return_always_ratio":    {0, 1},
+	"return_abandon_ratio":   {0, 1},
+	"return_abandon_turns":   {0, unbounded},
+	"return_ram_risk":        {0, 1},
+	"return_max_risk":        {0, 1},
+	"return_search_radius":   {0, unbounded},
+	"return_trip_risk_share": {0, 1},
+
+	"flee_cargo_ratio":       {0, 1},
+	"attack_cargo_ratio":     {0, 1},
+	"attack_min_enemy_cargo": {0, unbounded},
+	"attack_max_players":     {0, unbounded},
+
+	"predict_moves":           {0, 1},
+	"predict_prior_weight":    {1, unbounded},
+	"predict_full_ratio":      {0, 1},
+	"predict_min_ram_chance":  {0, 1},
+	"predict_contest_penalty": {0, 1},
+
+	"income_smoothing":     {0, 1},
+	"harvestable_ratio":    {0, 1},
+	"prior_mining_share":   {0, 1},
+	"warmup_income_turns":  {1, unbounded},
+	"spawn_profit_padding": {0, unbounded},
+
+	"dropoff_distance_point":     {1, unbounded},
+	"dropoff_distance_threshold": {0, unbounded},
+	"dropoff_site_radius":        {0, unbounded},
+	"dropoff_min_turns_left":     {1, unbounded},
+	"dropoff_replan_turns":       {1, unbounded},
+	"dropoff_value_ratio":        {0, unbounded},
+	"dropoff_keep_ratio":         {0, 1},
+	"dropoff_ships_per_dock":     {0, unbounded},
+
+	"influence_contest_margin": {0, unbounded},
+	"influence_site_ratio":     {0, 1},
+	"influence_mining_ratio":   {0, 1},
+
+	"analysis_max_clusters": {0, unbounded},
+	"analysis_rich_share":   {0, 1},
+	"init_analysis_millis":  {0, unbounded},
+
+	"turn_soft_millis": {0, unbounded},
+	"turn_hard_millis": {0, unbounded},
+}
+
+// LoadParams - Reads a JSON params file on top of the defaults. Keys missing from the file keep their default
+// or profile value, and profiles in the file are merged over the built in ones
+func LoadParams(path string) (*Params, error) {
+	p := DefaultParams()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("params: %s: %v", path, err)
+	}
+	fields := p.fields()
+	for key, value := range raw {
+		if key == "profiles" {
+			var profiles []Profile
+			if err := json.Unmarshal(value, &profiles); err != nil {
+				return nil, fmt.Errorf("params: %s: profiles: %v", path, err)
+			}
+			p.Profiles = mergeProfiles(p.Profiles, profiles)
+			continue
+		}
+		if _, ok := fields[key]; !ok {
+			return nil, fmt.Errorf("params: %s: unknown parameter %s", path, key)
+		}
+		if err := p.Set(key, string(value)); err != nil {
+			return nil, fmt.Errorf("params: %s: %v", path, err)
+		}
+	}
+	return p, nil
+}
+
+// Check - Checks the rules that tie parameters together, which Set can't check one value at a time. Call it once
+// the file, flags, strategy and profile are all applied
+func (p *Params) Check() error {
+	if p.DropoffDistancePoint <= p.DropoffDistanceThreshold {
+		return fmt.Errorf("params: dropoff_distance_point %d has to be above dropoff_distance_threshold %d",
+			p.DropoffDistancePoint, p.DropoffDistanceThreshold)
+	}
+	return nil
+}
+
+// ApplyProfile - Fills in the parameters nobody set explicitly from the profile for the map width and player count.
+// Returns the values that were applied
+func (p *Params) ApplyProfile(width, players int) map[string]float64 {
+	applied := make(map[string]float64)
+	for name, v := range ProfileValues(p.Profiles, width, players) {
+		if p.explicit[name] {
+			continue
+		}
+		if p.setValue(name, v) == nil {
+			applied[name] = v
+		}
+	}
+	return applied
+}
+
+// Save - Writes the params out as a JSON params file
+func (p *Params) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func (p *Params) String() string {
+	values := make(map[string]float64)
+	for _, name := range p.Names() {
+		values[name], _ = p.Get(name)
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// Names - Returns the json names of every parameter, sorted
+func (p *Params) Names() []string {
+	names := make([]string, 0)
+	for name := range p.fields() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get - Returns the value of the named parameter as a float
+func (p *Params) Get(name string) (float64, error) {
+	f, ok := p.fields()[name]
+	if !ok {
+		return 0, fmt.Errorf("params: unknown parameter %s", name)
+	}
+	if f.Kind() == reflect.Int {
+		return float64(f.Int()), nil
+	}
+	return f.Float(), nil
+}
+
+// IsInt - Checks whether the named parameter only takes whole numbers
+func (p *Params) IsInt(name string) bool {
+	f, ok := p.fields()[name]
+	return ok && f.Kind() == reflect.Int
+}
+
+// Set - Sets the named parameter from its text form. Parameters set this way win over profiles. Values out of the
+// parameter's bounds and fractions for whole number parameters are refused
+func (p *Params) Set(name, value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("params: %s: %v", name, err)
+	}
+	if p.IsInt(name) && v != math.Trunc(v) {
+		return fmt.Errorf("params: %s: %s is not a whole number", name, value)
+	}
+	if err := p.setValue(name, v); err != nil {
+		return err
+	}
+	if p.explicit == nil {
+		p.explicit = make(map[string]bool)
+	}
+	p.explicit[name] = true
+	return nil
+}
+
+// setValue - integer parameters are rounded, so interpolated profile values land on whole numbers
+func (p *Params) setValue(name string, v float64) error {
+	f, ok := p.fields()[name]
+	if !ok {
+		return fmt.Errorf("params: unknown parameter %s", name)
+	}
+	if f.Kind() == reflect.Int {
+		v = math.Round(v)
+	}
+	if b := paramBounds[name]; math.IsNaN(v) || math.IsInf(v, 0) || v < b[0] || v > b[1] {
+		return fmt.Errorf("params: %s: %g is out of range [%g, %g]", name, v, b[0], b[1])
+	}
+	if f.Kind() == reflect.Int {
+		f.SetInt(int64(math.Round(v)))
+		return nil
+	}
+	f.SetFloat(v)
+	return nil
+}
+
+// RegisterFlags - Adds a flag for every parameter. Call ApplyFlags after parsing, once any params file is loaded,
+// so flags win over the file
+func (p *Params) RegisterFlags(fs *flag.FlagSet) {
+	defaults := DefaultParams()
+	for _, name := range p.Names() {
+		v, _ := defaults.Get(name)
+		fs.String(name, strconv.FormatFloat(v, 'g', -1, 64), "strategy parameter "+name)
+	}
+}
+
+// ApplyFlags - Copies the parameter flags that were set on the command line into the params
+func (p *Params) ApplyFlags(fs *flag.FlagSet) error {
+	fields := p.fields()
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := fields[f.Name]; ok && err == nil {
+			err = p.Set(f.Name, f.Value.String())
+		}
+	})
+	return err
+}
+
+func (p *Params) fields() map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	v := reflect.ValueOf(p).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if k := t.Field(i).Type.Kind(); k != reflect.Int && k != reflect.Float64 {
+			continue
+		}
+		fields[t.Field(i).Tag.Get("json")] = v.Field(i)
+	}
+	return fields
+}
@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParamBounds(t *testing.T) {
+	p := DefaultParams()
+	for _, name := range p.Names() {
+		b, ok := paramBounds[name]
+		if !ok {
+			t.Errorf("%s has no bounds", name)
+			continue
+		}
+		if v, _ := p.Get(name); v < b[0] || v > b[1] {
+			t.Errorf("default %s = %g is out of range [%g, %g]", name, v, b[0], b[1])
+		}
+	}
+	for name := range paramBounds {
+		if _, err := p.Get(name); err != nil {
+			t.Errorf("bounds for unknown parameter %s", name)
+		}
+	}
+	if err := p.Check(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+	// every profile value has to be one the bot accepts, or ApplyProfile would skip it
+	for _, size := range []int{32, 40, 48, 56, 64} {
+		for _, players := range []int{2, 4} {
+			p := DefaultParams()
+			want := ProfileValues(p.Profiles, size, players)
+			if applied := p.ApplyProfile(size, players); len(applied) != len(want) {
+				t.Errorf("%dx%d: applied %d of %d profile values", size, players, len(applied), len(want))
+			}
+			if err := p.Check(); err != nil {
+				t.Errorf("%dx%d: %v", size, players, err)
+			}
+		}
+	}
+}
+
+func TestParamsSetRejects(t *testing.T) {
+	tests := []struct {
+		name, value string
+	}{
+		{"search_window_turns", "0"},
+		{"search_window_turns", "+Inf"},
+		{"search_depth", "NaN"},
+		{"dropoff_min_turns_left", "-5"},
+		{"flee_cargo_ratio", "1.5"},
+		{"search_depth", "4.5"},
+		{"search_window_base", "abc"},
+		{"no_such_param", "1"},
+	}
+	for _, tt := range tests {
+		p := DefaultParams()
+		before, _ := p.Get(tt.name)
+		err := p.Set(tt.name, tt.value)
+		if err == nil {
+			t.Errorf("Set(%s, %s) accepted", tt.name, tt.value)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.name) {
+			t.Errorf("Set(%s, %s): error %q does not name the parameter", tt.name, tt.value, err)
+		}
+		if after, _ := p.Get(tt.name); after != before {
+			t.Errorf("Set(%s, %s) changed the value to %g", tt.name, tt.value, after)
+		}
+	}
+
+	p := DefaultParams()
+	if err := p.Set("search_depth", "6"); err != nil {
+		t.Errorf("Set(search_depth, 6): %v", err)
+	}
+	if err := p.Set("flee_cargo_ratio", "0.75"); err != nil {
+		t.Errorf("Set(flee_cargo_ratio, 0.75): %v", err)
+	}
+}
+
+func TestParamsCheck(t *testing.T) {
+	p := DefaultParams()
+	p.Set("dropoff_distance_threshold", "13")
+	if err := p.Check(); err == nil || !strings.Contains(err.Error(), "dropoff_distance_point") {
+		t.Errorf("threshold equal to the point: %v, want an error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"helper"
+	"hlt"
+	"hlt/gameconfig"
+	"math"
+)
+
+// routeBurn - Works out the halite burned on the cheapest shortest route between src and target.
+// best[i][j] is the cheapest burn from i steps along xDir and j steps along yDir on to the target
+func routeBurn(gMap *hlt.GameMap, moveCost float64, src, target *hlt.Position) (best [][]float64, xDir, yDir *hlt.Direction) {
+	dirs := gMap.GetUnsafeMoves(src, target)
+	xDir, yDir = dirs[0], dirs[1]
+	nx, ny := 0, 0
+	if !xDir.Equals(hlt.Still()) {
+		nx = toroidalDistance(src.X(), target.X(), gMap.Width())
+	}
+	if !yDir.Equals(hlt.Still()) {
+		ny = toroidalDistance(src.Y(), target.Y(), gMap.Height())
+	}
+	sx, sy := 1, 1
+	if xDir.Equals(hlt.West()) {
+		sx = -1
+	}
+	if yDir.Equals(hlt.North()) {
+		sy = -1
+	}
+	w, h := gMap.Width(), gMap.Height()
+	best = make([][]float64, nx+1)
+	for i := 0; i <= nx; i++ {
+		best[i] = make([]float64, ny+1)
+		x := ((src.X()+i*sx)%w + w) % w
+		for j := 0; j <= ny; j++ {
+			y := ((src.Y()+j*sy)%h + h) % h
+			best[i][j] = math.Floor(float64(gMap.Cells[y][x].Halite) / moveCost)
+		}
+	}
+	for i := nx; i >= 0; i-- {
+		for j := ny; j >= 0; j-- {
+			if i == nx && j == ny {
+				best[i][j] = 0
+				continue
+			}
+			next := math.Inf(1)
+			if i < nx {
+				next = best[i+1][j]
+			}
+			if j < ny && best[i][j+1] < next {
+				next = best[i][j+1]
+			}
+			best[i][j] += next
+		}
+	}
+	return best, xDir, yDir
+}
+
+// pathBurn - Returns the halite a ship burns getting from src to target along the cheapest shortest route
+func (gm *GameAI) pathBurn(src, target *hlt.Position) float64 {
+	moveCost, _ := gm.config.GetDouble(gameconfig.MoveCostRatio)
+	best, _, _ := routeBurn(gm.game.Map, moveCost, src, target)
+	return best[0][0]
+}
+
+// cheapestSteps - Looks at every shortest route inside the rectangle between src and target and returns
+// the possible first steps ordered by how much halite the rest of the route burns
+func (move *MoveAI) cheapestSteps(src, target *hlt.Position) []*hlt.Direction {
+	moveCost, _ := move.gameAI.config.GetDouble(gameconfig.MoveCostRatio)
+	best, xDir, yDir := routeBurn(move.Map, moveCost, src, target)
+	nx, ny := len(best)-1, len(best[0])-1
+	switch {
+	case nx == 0 && ny == 0:
+		return []*hlt.Direction{}
+	case nx == 0:
+		return []*hlt.Direction{yDir}
+	case ny == 0:
+		return []*hlt.Direction{xDir}
+	case best[0][1] < best[1][0]:
+		return []*hlt.Direction{yDir, xDir}
+	}
+	return []*hlt.Direction{xDir, yDir}
+}
+
+// navigateCheaply - Steps towards the target along the route that burns the least halite.
+// Falls back to the lazy greedy search when the cheap steps are already claimed or the turn is short on time
+func (move *MoveAI) navigateCheaply(ship *hlt.Ship, target *hlt.Position) hlt.Command {
+	if ship.E.Pos.Equals(target) {
+		move.MarkFuturePos(ship.E.Pos)
+		return ship.StayStill()
+	}
+	steps := []*hlt.Direction{}
+	if !move.gameAI.deadline.OverSoft() {
+		steps = move.cheapestSteps(ship.E.Pos, target)
+	}
+	for _, d := range steps {
+		next := helper.NormalizedDirectionalOffset(ship.E.Pos, move.Map, d)
+		if !move.IsPosClaimed(next) {
+			move.MarkFuturePos(next)
+			move.path = []*hlt.Position{next}
+			return ship.Move(d)
+		}
+	}
+	return ship.Move(move.lazyGreedySearch(target, ship.E.Pos, move.gameAI.params.SearchDepth))
+}
+
+func toroidalDistance(a, b, size int) int {
+	d := abs(a - b)
+	if size-d < d {
+		return size - d
+	}
+	return d
+}
@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"helper"
+	"math"
+)
+
+// Phase - One step of planning a turn, run over our whole fleet
+type Phase struct {
+	Name string
+	Run  func()
+}
+
+// TurnPhases - The per turn planning path split into steps that can be timed on their own, ending with the whole
+// turn. Every run starts with no cells claimed and the halite we have banked, for the benchmarks
+func (gm *GameAI) TurnPhases() []Phase {
+	g := gm.game
+	ships := helper.SortedShips(g.Me.Ships)
+	params := gm.params
+	window := params.SearchWindowBase + int(math.Floor(float64(g.TurnNumber)/float64(params.SearchWindowTurns)))
+	move := func() *MoveAI {
+		return NewMoveAI(gm, g.Map, g.Me)
+	}
+	// a fresh time budget for every run, or the planners would cut corners once the first runs took a second,
+	// and the ledger the planners spend through starts over too
+	begin := func() {
+		gm.deadline = gm.NewDeadline()
+		gm.ledger.Begin(g.TurnNumber, g.Me.Halite)
+	}
+	return []Phase{
+		{"update", func() {
+			gm.Update(gm.NewDeadline())
+		}},
+		{"ship_logic", func() {
+			begin()
+			for _, ship := range ships {
+				gm.ShipLogic(ship)
+			}
+		}},
+		{"target_search", func() {
+			begin()
+			m := move()
+			for _, ship := range ships {
+				m.findMostHaliteInWindow(ship.E.Pos, window)
+			}
+		}},
+		{"pathfinding", func() {
+			begin()
+			m := move()
+			for _, ship := range ships {
+				dock, _ := gm.closestDropoff(ship.E.Pos)
+				m.lazyGreedySearch(dock, ship.E.Pos, params.SearchDepth)
+			}
+		}},
+		{"moves", func() {
+			begin()
+			m := move()
+			for _, ship := range ships {
+				m.Move(ship)
+			}
+		}},
+		{"convert_spawn", func() {
+			begin()
+			NewConvertAI(gm).DeterminePossibleDropOff(g.Me.Ships)
+			NewSpawnAI(gm).Spawn()
+		}},
+		{"turn", func() {
+			gm.Update(gm.NewDeadline())
+			gm.PlanTurn(move(), NewConvertAI(gm))
+		}},
+	}
+}
@@ -0,0 +1,99 @@
+package logic_test
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"scenario"
+	"testing"
+)
+
+// midGame - a mid game board: halite in patches, every player's fleet spread around its shipyard and a couple of
+// dropoffs each, our own ships included
+func midGame(size, players, ships, dropoffs, turn int, rng *rand.Rand) *scenario.Scenario {
+	s := scenario.New(size, size).Players(players).Turn(turn)
+	s.Constant("MAX_TURNS", 400+100*(size-32)/32)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			s.Halite(x, y, rng.Intn(120))
+		}
+	}
+	for patch := 0; patch < size/2; patch++ {
+		cx, cy, r := rng.Intn(size), rng.Intn(size), 2+rng.Intn(4)
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				s.Halite((cx+dx+size)%size, (cy+dy+size)%size, 300+rng.Intn(700))
+			}
+		}
+	}
+	taken := make(map[[2]int]bool)
+	for p := 0; p < players; p++ {
+		s.Bank(p, 3000+rng.Intn(5000))
+		// the default shipyard spots, placed explicitly so the fleet can be spread around them
+		x0, y0 := size/4, size/2
+		if players > 2 {
+			y0 = size / 4
+		}
+		if p%2 == 1 {
+			x0 = size - 1 - x0
+		}
+		if p >= 2 {
+			y0 = size - 1 - y0
+		}
+		s.Shipyard(p, x0, y0)
+		taken[[2]int{x0, y0}] = true
+		spread := size / 3
+		for d := 0; d < dropoffs; d++ {
+			x, y := (x0+rng.Intn(2*spread)-spread+size)%size, (y0+rng.Intn(2*spread)-spread+size)%size
+			if !taken[[2]int{x, y}] {
+				taken[[2]int{x, y}] = true
+				s.Dropoff(p, x, y)
+			}
+		}
+		for placed := 0; placed < ships; {
+			x, y := (x0+rng.Intn(2*spread)-spread+size)%size, (y0+rng.Intn(2*spread)-spread+size)%size
+			if taken[[2]int{x, y}] {
+				continue
+			}
+			taken[[2]int{x, y}] = true
+			s.Ship(p, x, y, rng.Intn(1000))
+			placed++
+		}
+	}
+	return s
+}
+
+// BenchmarkTurnPhases - Times every phase of GameAI.TurnPhases on a large 4 player game and a small 2 player one,
+// per turn and per ship of our fleet.
+// The whole turn has to stay well inside the engine's 2 second limit
+func BenchmarkTurnPhases(b *testing.B) {
+	boards := []struct {
+		size, players, ships int
+	}{
+		{64, 4, 80},
+		{32, 2, 30},
+	}
+	for _, board := range boards {
+		world, err := midGame(board.size, board.players, board.ships, 2, 200, rand.New(rand.NewSource(1))).World(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fleet := float64(len(world.Game.Me.Ships))
+		for _, phase := range world.AI.TurnPhases() {
+			run := phase.Run
+			b.Run(fmt.Sprintf("%dx%dp/%s", board.size, board.players, phase.Name), func(b *testing.B) {
+				b.ReportAllocs()
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				for i := 0; i < b.N; i++ {
+					run()
+				}
+				runtime.ReadMemStats(&after)
+				perShip := float64(b.N) * fleet
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/1e3/perShip, "us/ship")
+				b.ReportMetric(float64(after.Mallocs-before.Mallocs)/perShip, "allocs/ship")
+				b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/perShip, "B/ship")
+			})
+		}
+	}
+}
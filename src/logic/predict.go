@@ -0,0 +1,253 @@
+package logic
+
+import (
+	"helper"
+	"hlt"
+	"hlt/gameconfig"
+	"hlt/log"
+	"math"
+)
+
+// predictor rules, each names a set of moves it expects the ship to pick from
+const (
+	ruleMining    = iota // stayed and mined last turn, with halite still worth mining under it
+	ruleReturning        // carrying most of a full load, towards its closest dock
+	ruleMomentum         // moved last turn, the same way again
+	numRules
+)
+
+var ruleNames = [numRules]string{"mining", "returning", "momentum"}
+
+// ruleAccuracy - how often a ship is expected to follow each rule before we have seen an opponent play
+var ruleAccuracy = [numRules]float64{0.8, 0.7, 0.5}
+
+// enemyTrack - what we remember about an enemy ship from the previous turn
+type enemyTrack struct {
+	pos   *hlt.Position
+	cargo int
+	moved int           // index into hlt.AllDirections of its last move, -1 when unknown
+	mined bool          // stayed still and picked up halite
+	rules [numRules]int // moves each rule expected of it this turn as a bit set, 0 when the rule did not apply
+	stuck bool          // could not pay to move off its cell
+}
+
+// ruleStats - how often one opponent's ships did what a rule expected
+type ruleStats struct {
+	hits   float64
+	trials float64
+}
+
+// MovePredictor - Estimates where every enemy ship moves next turn from what it did lately: whether it is full
+// and heading for a dock, whether it mined its cell last turn and which way it was travelling. How much each of
+// those counts is learned per opponent as the game goes on
+type MovePredictor struct {
+	game   *hlt.Game
+	config *gameconfig.Constants
+	params *Params
+	tracks map[int]*enemyTrack
+	stats  map[int]*[numRules]ruleStats // per opponent player
+	probs  map[int][]float64            // per enemy ship, indexed like hlt.AllDirections
+}
+
+// NewMovePredictor - Generates a new MovePredictor object
+func NewMovePredictor(g *hlt.Game, c *gameconfig.Constants, p *Params) *MovePredictor {
+	return &MovePredictor{
+		game:   g,
+		config: c,
+		params: p,
+		tracks: make(map[int]*enemyTrack),
+		stats:  make(map[int]*[numRules]ruleStats),
+		probs:  make(map[int][]float64),
+	}
+}
+
+// Update - Scores last turn's expectations against the moves the enemy ships made and predicts their next ones.
+// Should be called once per turn, right after the frame is updated
+func (mp *MovePredictor) Update() {
+	g := mp.game
+	moveCost, _ := mp.config.GetDouble(gameconfig.MoveCostRatio)
+	maxHalite, _ := mp.config.GetInt(gameconfig.MaxHalite)
+	mp.probs = make(map[int][]float64)
+	seen := make(map[int]bool)
+	for _, p := range g.Players() {
+		if p.ID == g.Me.ID {
+			continue
+		}
+		stats, ok := mp.stats[p.ID]
+		if !ok {
+			stats = &[numRules]ruleStats{}
+			mp.stats[p.ID] = stats
+		}
+		docks := []*hlt.Position{p.Shipyard.E.Pos}
+		for _, d := range p.Dropoffs {
+			docks = append(docks, d.E.Pos)
+		}
+		for _, s := range helper.SortedShips(p.Ships) {
+			id := s.E.ID()
+			seen[id] = true
+			t, ok := mp.tracks[id]
+			if !ok {
+				t = &enemyTrack{moved: -1}
+				mp.tracks[id] = t
+			} else {
+				moved := mp.observed(t.pos, s.E.Pos)
+				// ships that could not move tell us nothing about their owner
+				if moved >= 0 && !t.stuck {
+					for r, set := range t.rules {
+						if set != 0 {
+							stats[r].trials++
+							if set&(1<<uint(moved)) != 0 {
+								stats[r].hits++
+							}
+						}
+					}
+				}
+				t.mined = moved == len(hlt.AllDirections)-1 && s.Halite > t.cargo
+				t.moved = moved
+			}
+			t.pos, t.cargo = s.E.Pos, s.Halite
+			cell := g.Map.AtEntity(s.E).Halite
+			t.stuck = s.Halite < int(math.Floor(float64(cell)/moveCost))
+			t.rules = mp.rules(t, s, cell, docks, maxHalite)
+			mp.probs[id] = mp.combine(stats, t)
+		}
+	}
+	for id := range mp.tracks {
+		if !seen[id] {
+			delete(mp.tracks, id)
+		}
+	}
+	if logger := log.GetInstance().Sub("predict"); logger.Enabled(log.Debug) {
+		accuracy := mp.Accuracy()
+		for _, p := range g.Players() {
+			if acc, ok := accuracy[p.ID]; ok {
+				logger.Debug("rule accuracy", log.Fields{"player": p.ID, "mining": acc[ruleNames[ruleMining]],
+					"returning": acc[ruleNames[ruleReturning]], "momentum": acc[ruleNames[ruleMomentum]]})
+			}
+		}
+	}
+}
+
+// observed - the index into hlt.AllDirections of the move that took a ship from one cell to the other, -1 when
+// no single move does
+func (mp *MovePredictor) observed(from, to *hlt.Position) int {
+	for i, d := range hlt.AllDirections {
+		if helper.NormalizedDirectionalOffset(from, mp.game.Map, d).Equals(to) {
+			return i
+		}
+	}
+	return -1
+}
+
+// rules - the moves each rule expects of the ship next turn
+func (mp *MovePredictor) rules(t *enemyTrack, s *hlt.Ship, cell int, docks []*hlt.Position, maxHalite int) [numRules]int {
+	var rules [numRules]int
+	still := 1 << uint(len(hlt.AllDirections)-1)
+	if t.mined && cell >= mp.params.MinMineHalite {
+		rules[ruleMining] = still
+	}
+	if float64(s.Halite) >= mp.params.PredictFullRatio*float64(maxHalite) {
+		var dock *hlt.Position
+		best := math.MaxInt32
+		for _, d := range docks {
+			if dis := mp.game.Map.CalculateDistance(s.E.Pos, d); dis < best {
+				dock, best = d, dis
+			}
+		}
+		for i, d := range hlt.AllDirections[:len(hlt.AllDirections)-1] {
+			if best > 0 && mp.game.Map.CalculateDistance(helper.NormalizedDirectionalOffset(s.E.Pos, mp.game.Map, d), dock) < best {
+				rules[ruleReturning] |= 1 << uint(i)
+			}
+		}
+	}
+	if t.moved >= 0 && t.moved < len(hlt.AllDirections)-1 {
+		rules[ruleMomentum] = 1 << uint(t.moved)
+	}
+	return rules
+}
+
+// combine - starts from every move being as likely and lets each rule that applies shift the odds towards the moves
+// it expects, by how often the owner's ships followed it so far
+func (mp *MovePredictor) combine(stats *[numRules]ruleStats, t *enemyTrack) []float64 {
+	n := len(hlt.AllDirections)
+	probs := make([]float64, n)
+	if t.stuck {
+		probs[n-1] = 1
+		return probs
+	}
+	for i := range probs {
+		probs[i] = 1 / float64(n)
+	}
+	for r, set := range t.rules {
+		if set == 0 {
+			continue
+		}
+		acc := mp.accuracy(stats, r)
+		size := 0
+		for i := 0; i < n; i++ {
+			if set&(1<<uint(i)) != 0 {
+				size++
+			}
+		}
+		for i := range probs {
+			if set&(1<<uint(i)) != 0 {
+				probs[i] *= acc / float64(size)
+			} else {
+				probs[i] *= (1 - acc) / float64(n-size)
+			}
+		}
+	}
+	total := 0.0
+	for _, p := range probs {
+		total += p
+	}
+	for i := range probs {
+		probs[i] /= total
+	}
+	return probs
+}
+
+// accuracy - share of the times the rule applied that the ship did what it expected, starting from the prior
+func (mp *MovePredictor) accuracy(stats *[numRules]ruleStats, rule int) float64 {
+	prior := mp.params.PredictPriorWeight
+	acc := (stats[rule].hits + prior*ruleAccuracy[rule]) / (stats[rule].trials + prior)
+	// a rule never gets to rule a move in or out completely
+	return math.Min(math.Max(acc, 0.02), 0.98)
+}
+
+// Probabilities - Returns the chance of each move the enemy ship makes next turn, indexed like hlt.AllDirections.
+// Every move is as likely for ships we know nothing about
+func (mp *MovePredictor) Probabilities(ship *hlt.Ship) []float64 {
+	if probs, ok := mp.probs[ship.E.ID()]; ok {
+		return probs
+	}
+	n := len(hlt.AllDirections)
+	probs := make([]float64, n)
+	for i := range probs {
+		probs[i] = 1 / float64(n)
+	}
+	return probs
+}
+
+// Chance - Returns the chance the enemy ship ends its next move on the position
+func (mp *MovePredictor) Chance(ship *hlt.Ship, pos *hlt.Position) float64 {
+	chance := 0.0
+	for i, p := range mp.Probabilities(ship) {
+		if helper.NormalizedDirectionalOffset(ship.E.Pos, mp.game.Map, hlt.AllDirections[i]).Equals(pos) {
+			chance += p
+		}
+	}
+	return chance
+}
+
+// Accuracy - Returns how often each opponent's ships followed each rule so far, keyed by player and rule name
+func (mp *MovePredictor) Accuracy() map[int]map[string]float64 {
+	all := make(map[int]map[string]float64, len(mp.stats))
+	for id, stats := range mp.stats {
+		all[id] = make(map[string]float64, numRules)
+		for r, name := range ruleNames {
+			all[id][name] = mp.accuracy(stats, r)
+		}
+	}
+	return all
+}
@@ -0,0 +1,90 @@
+package logic_test
+
+import (
+	"hlt"
+	"hlt/gameconfig"
+	"logic"
+	"scenario"
+	"testing"
+)
+
+// indexes into hlt.AllDirections
+const (
+	east  = 2
+	still = 4
+)
+
+// likeliest - the index into hlt.AllDirections of the move the predictor gives the highest chance
+func likeliest(probs []float64) int {
+	best := 0
+	for i, p := range probs {
+		if p > probs[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func TestMovePredictor(t *testing.T) {
+	// four players on their default shipyards: 0 at (8, 8), 1 at (23, 8), 2 at (8, 23) and 3 at (23, 23)
+	s := scenario.New(32, 32).Players(4).Turn(50)
+	s.Halite(5, 28, 400).Halite(26, 28, 500)
+	drifter := s.Ship(1, 20, 2, 0)
+	homing := s.Ship(1, 20, 8, 950)
+	straying := s.Ship(2, 11, 23, 950)
+	miner := s.Ship(2, 5, 28, 0)
+	stuck := s.Ship(3, 26, 28, 10)
+	w := world(t, s)
+
+	mp := logic.NewMovePredictor(w.Game, gameconfig.GetInstance(), logic.DefaultParams())
+	mp.Update()
+	// the next frame: player 1's ships both go east, the loaded one towards its shipyard, player 2's loaded ship
+	// goes east away from its shipyard and its miner picks up a quarter of its cell, player 3's ship can't move
+	w.Ship(drifter).E.Pos = hlt.NewPosition(21, 2)
+	w.Ship(homing).E.Pos = hlt.NewPosition(21, 8)
+	w.Ship(straying).E.Pos = hlt.NewPosition(12, 23)
+	w.Ship(miner).Halite = 100
+	w.Game.Map.AtPosition(hlt.NewPosition(5, 28)).Halite = 300
+	mp.Update()
+
+	if got := likeliest(mp.Probabilities(w.Ship(drifter))); got != east {
+		t.Errorf("ship that went east: likeliest move %s, want e", hlt.AllDirections[got])
+	}
+	if probs := mp.Probabilities(w.Ship(homing)); likeliest(probs) != east || probs[east] <= 0.5 {
+		t.Errorf("loaded ship heading home: %v, want most of the chance on e", probs)
+	}
+	if got := likeliest(mp.Probabilities(w.Ship(miner))); got != still {
+		t.Errorf("ship mining a rich cell: likeliest move %s, want o", hlt.AllDirections[got])
+	}
+	probs := mp.Probabilities(w.Ship(stuck))
+	for i, p := range probs {
+		want := 0.0
+		if i == still {
+			want = 1
+		}
+		if p != want {
+			t.Errorf("ship that can't pay to move: chance %g of %s, want %g", p, hlt.AllDirections[i], want)
+		}
+	}
+	if got := mp.Chance(w.Ship(stuck), w.Ship(stuck).E.Pos); got != 1 {
+		t.Errorf("ship that can't pay to move: chance %g to stay, want 1", got)
+	}
+
+	// only the returning rule applied on the first frame: player 1 followed it, player 2 did not
+	accuracy := mp.Accuracy()
+	prior := accuracy[3]["returning"]
+	if got := accuracy[1]["returning"]; got <= prior {
+		t.Errorf("player 1 returned home: accuracy %g, want above the prior %g", got, prior)
+	}
+	if got := accuracy[2]["returning"]; got >= prior {
+		t.Errorf("player 2 went the other way: accuracy %g, want below the prior %g", got, prior)
+	}
+	for _, rule := range []string{"mining", "momentum"} {
+		if accuracy[1][rule] != accuracy[3][rule] || accuracy[2][rule] != accuracy[3][rule] {
+			t.Errorf("%s accuracy moved before the rule applied: %v", rule, accuracy)
+		}
+	}
+	if _, ok := accuracy[0]; ok {
+		t.Error("accuracy kept for our own ships")
+	}
+}
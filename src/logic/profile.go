@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"math"
+	"sort"
+)
+
+// Profile - Parameter values for one map width and player count. Values are keyed by the json name of the parameter
+type Profile struct {
+	Width   int                `json:"width"`
+	Players int                `json:"players"`
+	Values  map[string]float64 `json:"values"`
+}
+
+// DefaultProfiles - Built in profiles for the smallest and largest maps in two and four player games.
+// Map sizes in between get values interpolated from these
+func DefaultProfiles() []Profile {
+	return []Profile{
+		{32, 2, map[string]float64{
+			"dropoff_distance_point": 12,
+			"dropoff_ships_per_dock": 8,
+			"dropoff_site_radius":    5,
+			"search_window_base":     4,
+		}},
+		{64, 2, map[string]float64{
+			"dropoff_distance_point":     16,
+			"dropoff_distance_threshold": 3,
+			"dropoff_ships_per_dock":     5,
+			"dropoff_site_radius":        7,
+			"search_window_base":         6,
+		}},
+		// four player games are crowded, so run from enemies earlier, never ram and be slower to build
+		{32, 4, map[string]float64{
+			"dropoff_distance_point": 11,
+			"dropoff_ships_per_dock": 10,
+			"dropoff_site_radius":    5,
+			"flee_cargo_ratio":       0.4,
+			"return_ram_risk":        0.15,
+			"spawn_profit_padding":   1.3,
+			"attack_max_players":     0,
+		}},
+		{64, 4, map[string]float64{
+			"dropoff_distance_point": 14,
+			"dropoff_ships_per_dock": 6,
+			"dropoff_site_radius":    6,
+			"flee_cargo_ratio":       0.45,
+			"return_ram_risk":        0.12,
+			"search_window_base":     5,
+			"attack_max_players":     0,
+		}},
+	}
+}
+
+// mergeProfiles - Overlays profiles on top of base ones. Values for the same width and player count are merged
+func mergeProfiles(base, overrides []Profile) []Profile {
+	merged := make([]Profile, 0, len(base)+len(overrides))
+	for _, p := range base {
+		values := make(map[string]float64, len(p.Values))
+		for k, v := range p.Values {
+			values[k] = v
+		}
+		merged = append(merged, Profile{p.Width, p.Players, values})
+	}
+	for _, o := range overrides {
+		found := false
+		for i := range merged {
+			if merged[i].Width == o.Width && merged[i].Players == o.Players {
+				for k, v := range o.Values {
+					merged[i].Values[k] = v
+				}
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// ProfileValues - Works out the profile values for a map width and player count. Profiles for the closest player
+// count are used and values are interpolated between the nearest widths on either side, taking the default for
+// a value one of them leaves out
+func ProfileValues(profiles []Profile, width, players int) map[string]float64 {
+	values := make(map[string]float64)
+	candidates := make([]Profile, 0)
+	bestGap := math.MaxInt32
+	for _, p := range profiles {
+		gap := abs(p.Players - players)
+		if gap < bestGap {
+			bestGap = gap
+			candidates = candidates[:0]
+		}
+		if gap == bestGap {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return values
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Width < candidates[j].Width
+	})
+	lower, upper := candidates[0], candidates[len(candidates)-1]
+	for _, p := range candidates {
+		if p.Width <= width {
+			lower = p
+		}
+	}
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].Width >= width {
+			upper = candidates[i]
+		}
+	}
+	t := 0.0
+	if upper.Width != lower.Width {
+		t = float64(width-lower.Width) / float64(upper.Width-lower.Width)
+		t = math.Max(0, math.Min(1, t))
+	}
+	// a value only one side sets is interpolated against the default, so it fades in rather than jumping
+	defaults := DefaultParams()
+	endpoint := func(p Profile, k string) (float64, bool) {
+		if v, ok := p.Values[k]; ok {
+			return v, true
+		}
+		v, err := defaults.Get(k)
+		return v, err == nil
+	}
+	for _, side := range []Profile{lower, upper} {
+		for k := range side.Values {
+			lv, lok := endpoint(lower, k)
+			uv, uok := endpoint(upper, k)
+			switch {
+			case lok && uok:
+				values[k] = lv + t*(uv-lv)
+			case lok:
+				values[k] = lv
+			default:
+				values[k] = uv
+			}
+		}
+	}
+	return values
+}
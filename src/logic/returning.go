@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"hlt"
+	"hlt/gameconfig"
+	"math"
+)
+
+// ReturnEstimate - Inputs and outcome of the decision to head back to a dock
+type ReturnEstimate struct {
+	Dock      *hlt.Position
+	Distance  int     // turns to the closest dock
+	Burn      float64 // halite burned on the way back
+	Gain      float64 // extra cargo expected from mining a few more turns nearby
+	Risk      float64 // chance of losing the cargo while mining the extra turns
+	TripRisk  float64 // chance of losing the cargo on the way back
+	ReturnNow float64 // halite per turn of the whole trip when heading back now
+	MineMore  float64 // halite per turn of the whole trip when mining the extra turns first
+}
+
+// estimateReturn - Compares the halite per turn of the ship's trip when it heads back now against topping up first.
+// The trip started when the ship last left a dock, so ships close to a dock feel the extra turns more and top up less
+func (gm *GameAI) estimateReturn(ship *hlt.Ship, elapsed int) ReturnEstimate {
+	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
+	params := gm.params
+	dock, d := gm.closestDropoff(ship.E.Pos)
+	est := ReturnEstimate{Dock: dock, Distance: d}
+	if gm.deadline.OverSoft() {
+		// short on time, guess the burn from the cell we are on instead of working out the cheapest route
+		moveCost, _ := gm.config.GetDouble(gameconfig.MoveCostRatio)
+		est.Burn = math.Floor(float64(gm.game.Map.AtEntity(ship.E).Halite)/moveCost) * float64(d)
+	} else {
+		est.Burn = gm.pathBurn(ship.E.Pos, dock)
+	}
+	est.Gain = gm.topUpGain(ship, params.ReturnLookahead)
+	sitting := math.Min(params.ReturnRamRisk*gm.threat.Expected(ship.E.Pos), params.ReturnMaxRisk)
+	est.TripRisk = 1 - math.Pow(1-sitting*params.ReturnTripRiskShare, float64(d))
+	est.Risk = 1 - math.Pow(1-sitting, float64(params.ReturnLookahead))
+	cargo := float64(ship.Halite)
+	later := math.Min(cargo+est.Gain, float64(maxHalite))
+	est.ReturnNow = (cargo - est.Burn) * (1 - est.TripRisk) / float64(elapsed+d+1)
+	est.MineMore = (later - est.Burn) * (1 - est.TripRisk) * (1 - est.Risk) / float64(elapsed+d+1+params.ReturnLookahead)
+	return est
+}
+
+// shouldReturn - Decides whether the ship heads back now. Returns the reason for the state machine history
+func (gm *GameAI) shouldReturn(ship *hlt.Ship, elapsed int) (bool, string) {
+	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
+	maxTurn, _ := gm.config.GetInt(gameconfig.MaxTurns)
+	cargo := float64(ship.Halite) / float64(maxHalite)
+	if cargo >= gm.params.ReturnAlwaysRatio {
+		return true, "cargo is full"
+	}
+	if cargo < gm.params.ReturnMinCargoRatio {
+		return false, ""
+	}
+	est := gm.estimateReturn(ship, elapsed)
+	if est.Distance+gm.params.ReturnLookahead >= maxTurn-gm.game.TurnNumber {
+		return true, "no time left to top up"
+	}
+	if est.ReturnNow >= est.MineMore {
+		return true, "heading back now beats topping up"
+	}
+	return false, ""
+}
+
+// topUpGain - Best halite the ship can pick up in the next few turns by mining its cell or one close by
+func (gm *GameAI) topUpGain(ship *hlt.Ship, turns int) float64 {
+	maxHalite, _ := gm.config.GetInt(gameconfig.MaxHalite)
+	moveCost, _ := gm.config.GetDouble(gameconfig.MoveCostRatio)
+	room := float64(maxHalite - ship.Halite)
+	here := gm.game.Map.AtEntity(ship.E)
+	best := gm.mineFor(here, turns, room)
+	w, h := gm.game.Map.Width(), gm.game.Map.Height()
+	pos := ship.E.Pos
+	radius := gm.params.ReturnSearchRadius
+	for dy := -radius; dy <= radius; dy++ {
+		span := radius - abs(dy)
+		y := ((pos.Y()+dy)%h + h) % h
+		for dx := -span; dx <= span; dx++ {
+			d := abs(dx) + abs(dy)
+			if d == 0 || d >= turns {
+				continue
+			}
+			x := ((pos.X()+dx)%w + w) % w
+			cell := gm.game.Map.Cells[y][x]
+			burn := math.Floor(float64(here.Halite)/moveCost) * float64(d)
+			if gain := gm.mineFor(cell, turns-d, room) - burn; gain > best {
+				best = gain
+			}
+		}
+	}
+	return math.Max(best, 0)
+}
+
+// mineFor - Halite collected by sitting on the cell for a number of turns, with the inspiration bonus when it applies
+func (gm *GameAI) mineFor(cell *hlt.MapCell, turns int, room float64) float64 {
+	extract, _ := gm.config.GetDouble(gameconfig.ExtractRatio)
+	bonus, _ := gm.config.GetDouble(gameconfig.InspiredBonusMultiplier)
+	inspired := gm.inspiration != nil && gm.inspiration.WouldBeInspired(cell.Pos)
+	halite := float64(cell.Halite)
+	total := 0.0
+	for i := 0; i < turns && total < room; i++ {
+		mined := math.Ceil(halite / extract)
+		halite -= mined
+		if inspired {
+			mined *= 1 + bonus
+		}
+		total += mined
+	}
+	return math.Min(total, room)
+}
@@ -0,0 +1,177 @@
+package logic
+
+import (
+	"hlt"
+	"hlt/gameconfig"
+	"hlt/log"
+	"math"
+)
+
+// incomeTracker - Keeps a running estimate of the halite each of our ships brings in per turn
+type incomeTracker struct {
+	lastHalite   int
+	lastShips    map[int]shipRecord
+	lastDropoffs map[int]bool
+	perShip      float64
+	samples      int
+}
+
+// shipRecord - where one of our ships was last turn, what it carried and the halite under it
+type shipRecord struct {
+	pos   *hlt.Position
+	cargo int
+	cell  int
+}
+
+func newIncomeTracker(g *hlt.Game) *incomeTracker {
+	return &incomeTracker{
+		lastHalite:   g.Me.Halite,
+		lastShips:    make(map[int]shipRecord),
+		lastDropoffs: make(map[int]bool),
+	}
+}
+
+// update - work out what we earned last turn from the change in banked halite plus whatever we spent. A conversion
+// only costs the bank what the ship's cargo and the halite under it did not cover
+func (it *incomeTracker) update(g *hlt.Game, c *gameconfig.Constants, smoothing float64) {
+	shipCost, _ := c.GetInt(gameconfig.ShipCost)
+	dropCost, _ := c.GetInt(gameconfig.DropoffCost)
+	me := g.Me
+	spent := 0
+	for id := range me.Ships {
+		if _, ok := it.lastShips[id]; !ok {
+			spent += shipCost
+		}
+	}
+	for id, d := range me.Dropoffs {
+		if it.lastDropoffs[id] {
+			continue
+		}
+		for _, r := range it.lastShips {
+			if d.E.Pos.Equals(r.pos) {
+				spent += int(math.Max(float64(dropCost-r.cargo-r.cell), 0))
+			}
+		}
+	}
+	if len(it.lastShips) > 0 {
+		income := float64(me.Halite - it.lastHalite + spent)
+		perShip := math.Max(income, 0) / float64(len(it.lastShips))
+		if it.samples == 0 {
+			it.perShip = perShip
+		} else {
+			it.perShip += smoothing * (perShip - it.perShip)
+		}
+		it.samples++
+	}
+	it.lastHalite = me.Halite
+	it.lastDropoffs = make(map[int]bool, len(me.Dropoffs))
+	for id := range me.Dropoffs {
+		it.lastDropoffs[id] = true
+	}
+	it.lastShips = make(map[int]shipRecord, len(me.Ships))
+	for id, s := range me.Ships {
+		it.lastShips[id] = shipRecord{s.E.Pos, s.Halite, g.Map.AtEntity(s.E).Halite}
+	}
+}
+
+// SpawnAI - Object to decide whether building another ship will pay for itself
+type SpawnAI struct {
+	game *GameAI
+}
+
+// NewSpawnAI - Generates a new SpawnAI object
+func NewSpawnAI(g *GameAI) *SpawnAI {
+	return &SpawnAI{
+		game: g,
+	}
+}
+
+// IncomePerShip - Returns the expected halite a ship brings in per turn, blending the
+// observed income with a prior from the map while we do not have much history
+func (sa *SpawnAI) IncomePerShip() float64 {
+	gm := sa.game
+	extract, _ := gm.config.GetDouble(gameconfig.ExtractRatio)
+	cells := gm.game.Map.Width() * gm.game.Map.Height()
+	prior := float64(gm.haliteLeft) / float64(cells) / extract * gm.params.PriorMiningShare
+	if gm.income.samples == 0 {
+		return prior
+	}
+	// ships only start bringing halite back after their first trip, so lean on the prior early on
+	w := math.Min(float64(gm.income.samples)/float64(gm.params.WarmupIncomeTurns), 1.0)
+	return w*gm.income.perShip + (1-w)*prior
+}
+
+// ExpectedProfit - Estimates the halite a new ship would bring in over the rest of the game
+func (sa *SpawnAI) ExpectedProfit() float64 {
+	gm := sa.game
+	maxTurn, _ := gm.config.GetInt(gameconfig.MaxTurns)
+	// a new ship needs to get out to the halite and make it back at the end
+	ramp := gm.game.Map.Width() / 4
+	turns := float64(maxTurn - gm.game.TurnNumber - ramp)
+	if turns <= 0 {
+		return 0
+	}
+	rate := sa.IncomePerShip()
+	harvestable := float64(gm.haliteLeft) * gm.params.HarvestableRatio
+	ours := len(gm.game.Me.Ships)
+	others := 0
+	for _, p := range gm.game.Players() {
+		if p.ID != gm.game.Me.ID {
+			others += len(p.Ships)
+		}
+	}
+	return sa.fleetHarvest(ours+1, others, rate, turns, harvestable) - sa.fleetHarvest(ours, others, rate, turns, harvestable)
+}
+
+// Spawn - Returns the spawn command when the shipyard is free and a new ship is worth it, paying for it through the ledger
+func (sa *SpawnAI) Spawn() hlt.Command {
+	g := sa.game.game
+	shipCost, _ := sa.game.config.GetInt(gameconfig.ShipCost)
+	var trace *SpawnTrace
+	if sa.game.tracer != nil {
+		trace = sa.game.tracer.spawn()
+		trace.Available = sa.game.ledger.Available()
+	}
+	if g.Map.AtEntity(g.Me.Shipyard.E).IsOccupied() {
+		if trace != nil {
+			trace.Occupied = true
+		}
+		return nil
+	}
+	profit := sa.ExpectedProfit()
+	threshold := float64(shipCost) * sa.game.params.SpawnProfitPadding
+	if trace != nil {
+		trace.Profit, trace.Threshold = profit, threshold
+	}
+	if profit <= threshold {
+		if logger := log.GetInstance().Sub("spawn"); logger.Enabled(log.Debug) {
+			logger.Debug("not worth a ship", log.Fields{"profit": math.Round(profit), "ships": len(g.Me.Ships)})
+		}
+		return nil
+	}
+	// the ledger refuses or defers the spawn when the halite is short or held for a dropoff
+	if !sa.game.ledger.Spend("spawn", shipCost) {
+		return nil
+	}
+	if trace != nil {
+		trace.Spawned = true
+	}
+	if logger := log.GetInstance().Sub("spawn"); logger.Enabled(log.Info) {
+		logger.Info("spawning", log.Fields{"profit": math.Round(profit), "ships": len(g.Me.Ships)})
+	}
+	return hlt.SpawnShip{}
+}
+
+// what our fleet of n ships picks up over the rest of the game when the other players have others ships.
+// every ship on the map drains the same halite, so the rate drops as the map empties
+func (sa *SpawnAI) fleetHarvest(n, others int, rate, turns, harvestable float64) float64 {
+	if harvestable <= 0 || n == 0 {
+		return 0
+	}
+	total := float64(n+others) * rate * turns
+	// halite runs down roughly linearly, so the average rate over the rest of the game is halfway to where it ends up
+	depletion := math.Min(total/harvestable, 1.0) / 2
+	fleet := float64(n) * rate * turns * (1 - depletion)
+	share := harvestable * float64(n) / float64(n+others)
+	return math.Min(fleet, share)
+}
@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"hlt"
+	"hlt/log"
+)
+
+const (
+	maxTransitionHistory = 50 // transitions kept per ship for debugging
+)
+
+// ShipState - Persistent state a ship is in between turns
+type ShipState int
+
+const (
+	// Exploring - Heading out to find halite to mine
+	Exploring = ShipState(iota)
+	// Mining - Sitting on a cell and collecting its halite
+	Mining
+	// Returning - Carrying cargo back to the closest drop off
+	Returning
+	// Building - Heading to the planned drop off site to convert
+	Building
+	// Fleeing - Getting cargo away from an enemy ship that could ram it
+	Fleeing
+	// Attacking - Ramming a loaded enemy ship
+	Attacking
+	// EndgameReturn - Going home for good at the end of the match
+	EndgameReturn
+)
+
+var shipStateNames = [...]string{"Exploring", "Mining", "Returning", "Building", "Fleeing", "Attacking", "EndgameReturn"}
+
+func (s ShipState) String() string {
+	if int(s) < len(shipStateNames) {
+		return shipStateNames[s]
+	}
+	return "Unknown"
+}
+
+// Transition - Record of a ship moving from one state to another
+type Transition struct {
+	Turn   int
+	From   ShipState
+	To     ShipState
+	Reason string
+}
+
+// shipMachine - State machine for a single ship that persists between turns
+type shipMachine struct {
+	id        int
+	state     ShipState
+	previous  ShipState // state before the last transition
+	since     int       // turn the current state was entered
+	tripStart int       // turn the ship last left a dock
+	targets   map[ShipState]*hlt.Position
+	history   []Transition
+	reason    string // why the ship made this turn's decision
+}
+
+func newShipMachine(id, turn int) *shipMachine {
+	return &shipMachine{
+		id:        id,
+		state:     Exploring,
+		since:     turn,
+		tripStart: turn,
+		targets:   make(map[ShipState]*hlt.Position),
+	}
+}
+
+// transition - Moves the ship into a new state and records why. Staying in the same state is a no-op
+func (m *shipMachine) transition(turn int, to ShipState, reason string) {
+	if m.state == to {
+		return
+	}
+	t := Transition{turn, m.state, to, reason}
+	if logger := log.GetInstance().Sub("move"); logger.Enabled(log.Info) {
+		logger.Info("state change", log.Fields{"ship": m.id, "from": t.From, "state": to, "target": m.targets[to], "reason": reason})
+	}
+	m.history = append(m.history, t)
+	if len(m.history) > maxTransitionHistory {
+		m.history = m.history[len(m.history)-maxTransitionHistory:]
+	}
+	m.previous = m.state
+	m.state = to
+	m.since = turn
+}
+
+// resumeState - State to go back to once a detour like fleeing or attacking is over
+func (m *shipMachine) resumeState() ShipState {
+	switch m.previous {
+	case Returning, Mining, EndgameReturn:
+		return m.previous
+	}
+	return Exploring
+}
+
+func (m *shipMachine) target(s ShipState) *hlt.Position {
+	return m.targets[s]
+}
+
+func (m *shipMachine) setTarget(s ShipState, pos *hlt.Position) {
+	m.targets[s] = pos
+}
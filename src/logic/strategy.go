@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+)
+
+// strategies - Named presets picked with --strategy. Values are keyed by the json name of the parameter and
+// sit between the profiles and the params file
+var strategies = map[string]map[string]float64{
+	"default": {},
+	// ram loaded enemies in any game and keep mining with more cargo around enemies
+	"aggressive": {
+		"attack_max_players":     4,
+		"attack_cargo_ratio":     0.4,
+		"attack_min_enemy_cargo": 300,
+		"flee_cargo_ratio":       0.7,
+	},
+	// never ram, run early and head home with less cargo
+	"safe": {
+		"attack_max_players":     0,
+		"flee_cargo_ratio":       0.3,
+		"return_ram_risk":        0.2,
+		"return_min_cargo_ratio": 0.4,
+	},
+	// play the whole game from the shipyard
+	"nodropoffs": {
+		"dropoff_min_turns_left": 1000000,
+	},
+}
+
+// StrategyNames - Returns the names accepted by ApplyStrategy, sorted
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyStrategy - Sets the values of the named strategy. Parameters already set explicitly keep their value,
+// the rest win over profiles like ones set from a file
+func (p *Params) ApplyStrategy(name string) error {
+	values, ok := strategies[name]
+	if !ok {
+		return fmt.Errorf("params: unknown strategy %q, expected one of %v", name, StrategyNames())
+	}
+	if p.explicit == nil {
+		p.explicit = make(map[string]bool)
+	}
+	for key, v := range values {
+		if p.explicit[key] {
+			continue
+		}
+		if err := p.setValue(key, v); err != nil {
+			return err
+		}
+		p.explicit[key] = true
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package logic
+
+import (
+	"helper"
+	"hlt"
+)
+
+// ThreatMap - Per turn view of which cells enemy ships can reach on their next move, and how likely they are to
+// end up on each when there are move predictions
+type ThreatMap struct {
+	gameMap   *hlt.GameMap
+	predictor *MovePredictor  // nil when every move of an enemy counts as certain
+	reach     [][][]*hlt.Ship // reach[y][x] - enemy ships that can end their next move on the cell
+	expected  [][]float64     // expected[y][x] - enemy ships expected to end their next move on the cell
+	contested [][]float64     // contested[y][x] - chance some enemy ship ends its next move on the cell
+}
+
+// NewThreatMap - Builds the threat map for the current turn. Without a predictor every enemy that can reach a
+// cell counts as one whole ship there
+func NewThreatMap(game *hlt.Game, predictor *MovePredictor) *ThreatMap {
+	h, w := game.Map.Height(), game.Map.Width()
+	tm := &ThreatMap{
+		gameMap:   game.Map,
+		predictor: predictor,
+		reach:     make([][][]*hlt.Ship, h),
+		expected:  make([][]float64, h),
+		contested: make([][]float64, h),
+	}
+	for y := range tm.reach {
+		tm.reach[y] = make([][]*hlt.Ship, w)
+		tm.expected[y] = make([]float64, w)
+		tm.contested[y] = make([]float64, w)
+	}
+	// contested starts as the chance no enemy lands on the cell
+	for y := range tm.contested {
+		for x := range tm.contested[y] {
+			tm.contested[y][x] = 1
+		}
+	}
+	for _, p := range game.Players() {
+		if p.ID == game.Me.ID {
+			continue
+		}
+		for _, s := range helper.SortedShips(p.Ships) {
+			probs := []float64{1, 1, 1, 1, 1}
+			if predictor != nil {
+				probs = predictor.Probabilities(s)
+			}
+			for i, d := range hlt.AllDirections {
+				pos := helper.NormalizedDirectionalOffset(s.E.Pos, game.Map, d)
+				tm.reach[pos.Y()][pos.X()] = append(tm.reach[pos.Y()][pos.X()], s)
+				tm.expected[pos.Y()][pos.X()] += probs[i]
+				if predictor != nil {
+					tm.contested[pos.Y()][pos.X()] *= 1 - probs[i]
+				}
+			}
+		}
+	}
+	for y := range tm.contested {
+		for x := range tm.contested[y] {
+			tm.contested[y][x] = 1 - tm.contested[y][x]
+		}
+	}
+	return tm
+}
+
+// EnemiesReaching - Returns the enemy ships that can move onto the position next turn
+func (tm *ThreatMap) EnemiesReaching(pos *hlt.Position) []*hlt.Ship {
+	return tm.reach[pos.Y()][pos.X()]
+}
+
+// Threat - Returns how many enemy ships can move onto the position next turn
+func (tm *ThreatMap) Threat(pos *hlt.Position) int {
+	return len(tm.reach[pos.Y()][pos.X()])
+}
+
+// Expected - Returns how many enemy ships are expected to move onto the position next turn, the same as Threat
+// without move predictions
+func (tm *ThreatMap) Expected(pos *hlt.Position) float64 {
+	return tm.expected[pos.Y()][pos.X()]
+}
+
+// Contested - Returns the chance some enemy ship ends its next move on the position, 0 without move predictions
+func (tm *ThreatMap) Contested(pos *hlt.Position) float64 {
+	return tm.contested[pos.Y()][pos.X()]
+}
+
+// Chance - Returns the chance the enemy ship ends its next move on the position, 1 for every cell it can reach
+// without move predictions
+func (tm *ThreatMap) Chance(enemy *hlt.Ship, pos *hlt.Position) float64 {
+	if tm.predictor == nil {
+		return 1
+	}
+	return tm.predictor.Chance(enemy, pos)
+}
@@ -0,0 +1,146 @@
+package logic
+
+import (
+	"encoding/json"
+	"hlt"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	maxTraceCandidates = 5 // best scored cells kept per ship
+)
+
+// Point - Map position in a trace
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func pointOf(pos *hlt.Position) *Point {
+	if pos == nil {
+		return nil
+	}
+	return &Point{pos.X(), pos.Y()}
+}
+
+// Candidate - Cell a planner considered and the score it gave it
+type Candidate struct {
+	Pos   Point   `json:"pos"`
+	Score float64 `json:"score"`
+}
+
+// ShipTrace - Everything that went into one ship's move this turn
+type ShipTrace struct {
+	ID         int         `json:"id"`
+	Pos        *Point      `json:"pos"`
+	Cargo      int         `json:"cargo"`
+	State      string      `json:"state"`
+	Decision   string      `json:"decision"`
+	Reason     string      `json:"reason"`
+	Target     *Point      `json:"target,omitempty"`
+	Path       []Point     `json:"path,omitempty"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+	Command    string      `json:"command"`
+}
+
+// SpawnTrace - Inputs and outcome of the spawn decision
+type SpawnTrace struct {
+	Occupied  bool    `json:"occupied"`
+	Profit    float64 `json:"profit"`
+	Threshold float64 `json:"threshold"`
+	Available int     `json:"available"`
+	Spawned   bool    `json:"spawned"`
+}
+
+// DropoffTrace - State of the dropoff plan and whether a ship converted
+type DropoffTrace struct {
+	Target    *Point  `json:"target,omitempty"`
+	Builder   int     `json:"builder"`
+	Score     float64 `json:"score"`
+	Reserved  int     `json:"reserved"`
+	Cost      int     `json:"cost"`
+	Converted bool    `json:"converted"`
+}
+
+// TurnTrace - One line of the trace, everything decided in a turn
+type TurnTrace struct {
+	Turn      int           `json:"turn"`
+	Halite    int           `json:"halite"`
+	ElapsedMs float64       `json:"elapsed_ms"`
+	Ships     []*ShipTrace  `json:"ships"`
+	Spawn     *SpawnTrace   `json:"spawn,omitempty"`
+	Dropoff   *DropoffTrace `json:"dropoff,omitempty"`
+	Repairs   []Problem     `json:"repairs,omitempty"`
+}
+
+// Tracer - Writes a JSON line per turn with every decision the bot made. A nil Tracer records nothing
+type Tracer struct {
+	enc   *json.Encoder
+	turn  *TurnTrace
+	ships map[int]*ShipTrace
+}
+
+// NewTracer - Generates a Tracer writing to w
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{enc: json.NewEncoder(w)}
+}
+
+// Begin - Starts the record of a new turn
+func (t *Tracer) Begin(turn, halite int) {
+	if t == nil {
+		return
+	}
+	t.turn = &TurnTrace{Turn: turn, Halite: halite, Ships: make([]*ShipTrace, 0)}
+	t.ships = make(map[int]*ShipTrace)
+}
+
+// End - Writes out the turn, ships sorted by ID
+func (t *Tracer) End(elapsed time.Duration) error {
+	if t == nil || t.turn == nil {
+		return nil
+	}
+	sort.Slice(t.turn.Ships, func(i, j int) bool {
+		return t.turn.Ships[i].ID < t.turn.Ships[j].ID
+	})
+	t.turn.ElapsedMs = float64(elapsed) / float64(time.Millisecond)
+	err := t.enc.Encode(t.turn)
+	t.turn = nil
+	return err
+}
+
+func (t *Tracer) ship(ship *hlt.Ship) *ShipTrace {
+	st, ok := t.ships[ship.E.ID()]
+	if !ok {
+		st = &ShipTrace{ID: ship.E.ID(), Pos: pointOf(ship.E.Pos), Cargo: ship.Halite}
+		t.ships[ship.E.ID()] = st
+		t.turn.Ships = append(t.turn.Ships, st)
+	}
+	return st
+}
+
+// candidates - keeps the best few, highest score first
+func (t *Tracer) candidates(ship *hlt.Ship, all []Candidate) {
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Score > all[j].Score
+	})
+	if len(all) > maxTraceCandidates {
+		all = all[:maxTraceCandidates]
+	}
+	t.ship(ship).Candidates = all
+}
+
+func (t *Tracer) spawn() *SpawnTrace {
+	if t.turn.Spawn == nil {
+		t.turn.Spawn = &SpawnTrace{}
+	}
+	return t.turn.Spawn
+}
+
+func (t *Tracer) dropoff() *DropoffTrace {
+	if t.turn.Dropoff == nil {
+		t.turn.Dropoff = &DropoffTrace{Builder: -1}
+	}
+	return t.turn.Dropoff
+}
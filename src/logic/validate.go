@@ -0,0 +1,228 @@
+package logic
+
+import (
+	"helper"
+	"hlt"
+	"hlt/gameconfig"
+	"hlt/log"
+)
+
+// Problem - A command the validator dropped or changed before it went to the engine
+type Problem struct {
+	Command string `json:"command"` // the command as the bot made it
+	Ship    int    `json:"ship"`    // ship the command is for, -1 for a spawn
+	Reason  string `json:"reason"`
+	Repair  string `json:"repair,omitempty"` // what was sent instead, empty when the command was dropped
+}
+
+// validated - a command still in the batch and what it does
+type validated struct {
+	com  hlt.Command
+	ship *hlt.Ship
+	dir  *hlt.Direction // nil for conversions and spawns
+}
+
+// Validate - Checks a whole turn of commands and repairs it, so a bug in one planner costs a ship its move
+// rather than the bot its game. Unknown commands, commands for ships we don't own and second commands for a ship
+// are dropped, as are spawns and conversions we can't pay for, a spawn onto a shipyard one of our ships ends the
+// turn on and a conversion on a structure. A ship that can't pay for its move and friendly ships ending on the same cell are
+// held still instead, except for the ships crashing home in the last turns of the game
+func (gm *GameAI) Validate(commands []hlt.Command) ([]hlt.Command, []Problem) {
+	g := gm.game
+	me := g.Me
+	var problems []Problem
+	report := func(com hlt.Command, ship int, reason string, repair hlt.Command) {
+		p := Problem{Command: com.CommandString(), Ship: ship, Reason: reason}
+		if repair != nil {
+			p.Repair = repair.CommandString()
+		}
+		problems = append(problems, p)
+		log.GetInstance().Sub("validate").Warn("repaired command", log.Fields{"command": p.Command, "ship": ship,
+			"reason": reason, "repair": p.Repair})
+	}
+
+	// ownership and one command per ship
+	var batch []*validated
+	handled := make(map[int]bool)
+	spawning := false
+	for _, com := range commands {
+		var id int
+		var dir *hlt.Direction
+		switch c := com.(type) {
+		case *hlt.Move:
+			id, dir = c.ShipID(), c.Direction()
+		case hlt.Move:
+			id, dir = c.ShipID(), c.Direction()
+		case *hlt.TransformToDropoff:
+			id = c.ShipID()
+		case hlt.TransformToDropoff:
+			id = c.ShipID()
+		case hlt.SpawnShip, *hlt.SpawnShip:
+			if spawning {
+				report(com, -1, "second spawn this turn", nil)
+				continue
+			}
+			spawning = true
+			batch = append(batch, &validated{com: com})
+			continue
+		default:
+			report(com, -1, "unknown command", nil)
+			continue
+		}
+		ship, ok := me.Ships[id]
+		switch {
+		case !ok:
+			report(com, id, "not one of our ships", nil)
+		case handled[id]:
+			report(com, id, "second command for the ship", nil)
+		default:
+			handled[id] = true
+			batch = append(batch, &validated{com, ship, dir})
+		}
+	}
+
+	// conversions first since their cargo is credited, then the spawn out of what is left
+	available := me.Halite
+	dropCost, _ := gm.config.GetInt(gameconfig.DropoffCost)
+	shipCost, _ := gm.config.GetInt(gameconfig.ShipCost)
+	converting := make(map[int]bool)
+	for i, v := range batch {
+		if v.ship == nil || v.dir != nil {
+			continue
+		}
+		cell := g.Map.AtEntity(v.ship.E)
+		cost := dropCost - v.ship.Halite - cell.Halite
+		if cost < 0 {
+			cost = 0
+		}
+		switch {
+		case cell.HasStructure() || gm.isShipyard(v.ship.E.Pos):
+			report(v.com, v.ship.E.ID(), "conversion on a structure", nil)
+			batch[i] = nil
+		case cost > available:
+			report(v.com, v.ship.E.ID(), "can't pay for the dropoff", nil)
+			batch[i] = nil
+		default:
+			available -= cost
+			converting[v.ship.E.ID()] = true
+		}
+	}
+
+	// moves the cargo can't pay for
+	moveRatio, _ := gm.config.GetInt(gameconfig.MoveCostRatio)
+	for _, v := range batch {
+		if v == nil || v.dir == nil || v.dir.Equals(hlt.Still()) || moveRatio <= 0 {
+			continue
+		}
+		if cost := g.Map.AtEntity(v.ship.E).Halite / moveRatio; v.ship.Halite < cost {
+			still := v.ship.StayStill()
+			report(v.com, v.ship.E.ID(), "not enough cargo to pay the move", still)
+			v.com, v.dir = still, hlt.Still()
+		}
+	}
+
+	// friendly ships ending on the same cell, ships without a command stay where they are
+	moves := make(map[int]*validated)
+	for _, v := range batch {
+		if v != nil && v.dir != nil {
+			moves[v.ship.E.ID()] = v
+		}
+	}
+	rush := gm.endgameRush()
+	for changed := true; changed; {
+		changed = false
+		ends := make(map[string][]*hlt.Ship)
+		var cells []string // in order of the first ship ending there, so repairs come out the same every run
+		for _, ship := range helper.SortedShips(me.Ships) {
+			if converting[ship.E.ID()] {
+				continue
+			}
+			pos := gm.endOf(ship, moves)
+			if _, ok := ends[pos.String()]; !ok {
+				cells = append(cells, pos.String())
+			}
+			ends[pos.String()] = append(ends[pos.String()], ship)
+		}
+		for _, cell := range cells {
+			ships := ends[cell]
+			if len(ships) < 2 || (rush && gm.onDropOff(gm.endOf(ships[0], moves))) {
+				continue
+			}
+			// a ship staying keeps the cell, otherwise the first ship moving in does
+			keep := -1
+			for i, ship := range ships {
+				if v, ok := moves[ship.E.ID()]; !ok || v.dir.Equals(hlt.Still()) {
+					keep = i
+				}
+			}
+			if keep < 0 {
+				keep = 0
+			}
+			for i, ship := range ships {
+				if i == keep {
+					continue
+				}
+				v, ok := moves[ship.E.ID()]
+				if !ok || v.dir.Equals(hlt.Still()) {
+					// two ships staying can't be on one cell, the engine would not have sent that
+					continue
+				}
+				still := ship.StayStill()
+				report(v.com, ship.E.ID(), "moving onto a cell another of our ships ends on", still)
+				v.com, v.dir = still, hlt.Still()
+				changed = true
+			}
+		}
+	}
+
+	// the spawn last, once we know where every ship ends up
+	for i, v := range batch {
+		if v == nil || v.ship != nil {
+			continue
+		}
+		yard := me.Shipyard.E.Pos
+		blocked := false
+		for _, ship := range me.Ships {
+			if !converting[ship.E.ID()] && gm.endOf(ship, moves).Equals(yard) {
+				blocked = true
+			}
+		}
+		switch {
+		case shipCost > available:
+			report(v.com, -1, "can't pay for the ship", nil)
+			batch[i] = nil
+		case blocked:
+			report(v.com, -1, "one of our ships ends the turn on the shipyard", nil)
+			batch[i] = nil
+		}
+	}
+
+	repaired := make([]hlt.Command, 0, len(batch))
+	for _, v := range batch {
+		if v != nil {
+			repaired = append(repaired, v.com)
+		}
+	}
+	if gm.tracer != nil && gm.tracer.turn != nil {
+		gm.tracer.turn.Repairs = problems
+	}
+	return repaired, problems
+}
+
+// endOf - where the ship ends the turn with its command
+func (gm *GameAI) endOf(ship *hlt.Ship, moves map[int]*validated) *hlt.Position {
+	if v, ok := moves[ship.E.ID()]; ok {
+		return helper.NormalizedDirectionalOffset(ship.E.Pos, gm.game.Map, v.dir)
+	}
+	return ship.E.Pos
+}
+
+// isShipyard - whether any player's shipyard is on the position
+func (gm *GameAI) isShipyard(pos *hlt.Position) bool {
+	for _, p := range gm.game.Players() {
+		if p.Shipyard.E.Pos.Equals(pos) {
+			return true
+		}
+	}
+	return false
+}
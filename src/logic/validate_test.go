@@ -0,0 +1,118 @@
+package logic_test
+
+import (
+	"hlt"
+	"reflect"
+	"scenario"
+	"testing"
+)
+
+// unknown - a command the validator has never heard of
+type unknown struct{}
+
+func (unknown) CommandString() string { return "x" }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command
+		want     []string
+		problems int
+	}{
+		{"valid turn passes", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 1000)
+			a := s.Ship(0, 10, 10, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.North()), hlt.SpawnShip{}}
+			}
+		}, []string{"m 0 n", "g"}, 0},
+		{"second command for a ship", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			a := s.Ship(0, 10, 10, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.North()), w.Ship(a).Move(hlt.South())}
+			}
+		}, []string{"m 0 n"}, 1},
+		{"enemy ship", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			e := s.Ship(1, 20, 10, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(e).Move(hlt.North())}
+			}
+		}, []string{}, 1},
+		{"move the cargo can't pay for", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Halite(10, 10, 500)
+			a := s.Ship(0, 10, 10, 20)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.East())}
+			}
+		}, []string{"m 0 o"}, 1},
+		{"two ships onto one cell", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			a := s.Ship(0, 10, 10, 0)
+			b := s.Ship(0, 12, 10, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.East()), w.Ship(b).Move(hlt.West())}
+			}
+		}, []string{"m 0 e", "m 1 o"}, 1},
+		{"moving onto a ship that stays", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			a := s.Ship(0, 10, 10, 0)
+			b := s.Ship(0, 11, 10, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.East()), w.Ship(b).StayStill()}
+			}
+		}, []string{"m 0 o", "m 1 o"}, 1},
+		{"spawn onto a ship ending on the shipyard", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 1000)
+			a := s.Ship(0, 9, 16, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).Move(hlt.West()), hlt.SpawnShip{}}
+			}
+		}, []string{"m 0 w"}, 1},
+		{"second spawn", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 5000)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{hlt.SpawnShip{}, hlt.SpawnShip{}}
+			}
+		}, []string{"g"}, 1},
+		{"conversion and spawn share the bank", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 3500).Halite(10, 10, 200)
+			a := s.Ship(0, 10, 10, 300)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).MakeDropoff(), hlt.SpawnShip{}}
+			}
+		}, []string{"c 0"}, 1},
+		{"value commands count like pointers", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 5000)
+			a := s.Ship(0, 10, 10, 0)
+			b := s.Ship(0, 20, 20, 0)
+			return func(w *scenario.World) []hlt.Command {
+				move := *w.Ship(a).Move(hlt.North()).(*hlt.Move)
+				convert := *w.Ship(b).MakeDropoff().(*hlt.TransformToDropoff)
+				return []hlt.Command{move, w.Ship(a).Move(hlt.South()), convert}
+			}
+		}, []string{"m 0 n", "c 1"}, 1},
+		{"unknown command", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{unknown{}}
+			}
+		}, []string{}, 1},
+		{"conversion on the shipyard", func(s *scenario.Scenario) func(w *scenario.World) []hlt.Command {
+			s.Bank(0, 5000)
+			a := s.Ship(0, 8, 16, 0)
+			return func(w *scenario.World) []hlt.Command {
+				return []hlt.Command{w.Ship(a).MakeDropoff()}
+			}
+		}, []string{}, 1},
+	}
+	for _, tt := range tests {
+		s := board(50)
+		commands := tt.setup(s)
+		w := world(t, s)
+		repaired, problems := w.AI.Validate(commands(w))
+		got := []string{}
+		for _, com := range repaired {
+			got = append(got, com.CommandString())
+		}
+		if !reflect.DeepEqual(got, tt.want) || len(problems) != tt.problems {
+			t.Errorf("%s: %v with %d problems %v, want %v with %d", tt.name, got, len(problems), problems, tt.want, tt.problems)
+		}
+	}
+}
@@ -1,16 +1,21 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"helper"
 	"hlt"
 	"hlt/gameconfig"
+	"hlt/input"
 	"hlt/log"
+	"io"
 	"logic"
-	"math"
 	"math/rand"
 	"os"
 	"os/signal"
-	"strconv"
+	"path/filepath"
+	"render"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -29,60 +34,212 @@ func gracefulExit(logger *log.FileLogger) {
 	}()
 }
 
+func exitWith(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// printDecisions - dry run output, one line per command with the state of the ship behind it
+func printDecisions(gameAI *logic.GameAI, game *hlt.Game, commands []hlt.Command) {
+	fmt.Printf("turn %d: %d halite, %d ships\n", game.TurnNumber, game.Me.Halite, len(game.Me.Ships))
+	for _, com := range commands {
+		fmt.Printf("  %s\n", com.CommandString())
+	}
+	for _, ship := range helper.SortedShips(game.Me.Ships) {
+		fmt.Printf("  ship %d at %s carrying %d: %s\n", ship.E.ID(), ship.E.Pos, ship.Halite, gameAI.ShipState(ship))
+	}
+}
+
+// writeSnapshot - turn-NNN.png or .svg with the field as a heatmap and this turn's moves and claims on top
+func writeSnapshot(gameAI *logic.GameAI, game *hlt.Game, commands []hlt.Command, claimed map[string]*hlt.MapCell, dir, field, format string) error {
+	values, err := gameAI.Field(field)
+	if err != nil {
+		return err
+	}
+	opts := render.ImageOptions{Field: values, Commands: commands}
+	for _, cell := range claimed {
+		opts.Claimed = append(opts.Claimed, cell.Pos)
+	}
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("turn-%03d.%s", game.TurnNumber, format)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "svg" {
+		return render.WriteSVG(f, game, opts)
+	}
+	return render.WritePNG(f, game, opts)
+}
+
 func main() {
-	args := os.Args
-	var seed = time.Now().UnixNano() % int64(os.Getpid())
-	if len(args) > 1 {
-		seed, _ = strconv.ParseInt(args[0], 10, 64)
+	var (
+		seedFlag    = flag.Int64("seed", -1, "seed for the bot rng, a time based one when negative")
+		paramsFile  = flag.String("params", "", "JSON file with strategy parameters, flags below override it")
+		name        = flag.String("name", "jm", "bot name sent to the engine")
+		strategy    = flag.String("strategy", "default", "strategy preset, one of "+strings.Join(logic.StrategyNames(), ", "))
+		logDir      = flag.String("log-dir", ".", "directory the bot log is written to")
+		logLevel    = flag.String("log-level", "info", "lowest level logged: debug, info, warn, error or off, per subsystem with e.g. info,move=debug,spawn=off")
+		logFormat   = flag.String("log-format", "text", "log file format: text, json for JSON lines, or none to skip logging")
+		recordInput = flag.String("record-input", "", "copy everything the engine sends to this file")
+		replayInput = flag.String("replay-input", "", "read the game from a file recorded with -record-input instead of stdin")
+		traceFile   = flag.String("trace", "", "write every decision of every turn to this file as JSON lines")
+		renderMode  = flag.String("render", "", "draw the board every turn, ascii or ansi: printed in a dry run and logged at debug level otherwise")
+		renderCrop  = flag.Int("render-radius", 0, "only draw this many cells around our shipyard, zero draws the whole board")
+		snapDir     = flag.String("snapshot-dir", "", "write an image of the board every turn to this directory")
+		snapField   = flag.String("snapshot-field", "halite", "heatmap under the board: "+strings.Join(logic.FieldNames(), ", "))
+		snapFormat  = flag.String("snapshot-format", "png", "snapshot format, png or svg")
+		snapEvery   = flag.Int("snapshot-every", 1, "only snapshot every this many turns")
+		dryRun      = flag.Bool("dry-run", false, "play a recorded game from -replay-input and print the decisions instead of commands")
+	)
+	var params = logic.DefaultParams()
+	params.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if *paramsFile != "" {
+		var err error
+		if params, err = logic.LoadParams(*paramsFile); err != nil {
+			exitWith(err)
+		}
+	}
+	if err := params.ApplyFlags(flag.CommandLine); err != nil {
+		exitWith(err)
+	}
+	if err := params.ApplyStrategy(*strategy); err != nil {
+		exitWith(err)
+	}
+	level, subsystems, err := log.ParseLevels(*logLevel)
+	if err != nil {
+		exitWith(err)
+	}
+	log.SetLevel(level)
+	for name, l := range subsystems {
+		log.SetSubsystemLevel(name, l)
+	}
+	if err := log.SetFormat(*logFormat); err != nil {
+		exitWith(err)
+	}
+	log.SetDir(*logDir)
+	if *renderMode != "" && *renderMode != "ascii" && *renderMode != "ansi" {
+		exitWith(fmt.Errorf("-render must be ascii or ansi"))
+	}
+	if *snapFormat != "png" && *snapFormat != "svg" {
+		exitWith(fmt.Errorf("-snapshot-format must be png or svg"))
+	}
+	if *snapDir != "" {
+		if err := os.MkdirAll(*snapDir, 0755); err != nil {
+			exitWith(err)
+		}
+	}
+	if *dryRun && *replayInput == "" {
+		exitWith(fmt.Errorf("-dry-run needs a recorded game from -replay-input"))
+	}
+
+	var source io.Reader = os.Stdin
+	if *replayInput != "" {
+		f, err := os.Open(*replayInput)
+		if err != nil {
+			exitWith(err)
+		}
+		defer f.Close()
+		source = f
+	}
+	if *recordInput != "" {
+		f, err := os.Create(*recordInput)
+		if err != nil {
+			exitWith(err)
+		}
+		defer f.Close()
+		source = io.TeeReader(source, f)
+	}
+	input.SetSource(source)
+
+	var seed = *seedFlag
+	if seed < 0 {
+		seed = time.Now().UnixNano() % int64(os.Getpid())
 	}
 	rand.Seed(seed)
 
 	var game = hlt.NewGame()
+	if game == nil {
+		exitWith(fmt.Errorf("no game in input"))
+	}
+	var initStart = time.Now()
 	// At this point "game" variable is populated with initial map data.
 	// This is a good place to do computationally expensive start-up pre-processing.
 	// As soon as you call "ready" function below, the 2 second per turn timer will start.
 
-	// TODO scan board with a window to build a hueristic of most desirable locations on map. Set in GameAI
-	// determine window by taking  width/8  to yield window size. example 32x32 map -> 32/8 yields window of size 4
-
 	var config = gameconfig.GetInstance()
+	// parameters not set from the params file or flags come from the profile for this map size and player count
+	profile := params.ApplyProfile(game.Map.Width(), game.NumPlayers())
+	if err := params.Check(); err != nil {
+		exitWith(err)
+	}
 	// Setup GameAI to persist data between frames
-	gameAI := logic.NewGameAI(game, config)
-	maxShipCount := 12
+	gameAI := logic.NewGameAI(game, config, params)
 
 	fileLogger := log.NewFileLogger(game.Me.ID)
-	var logger = fileLogger.Logger
-	logger.Printf("Successfully created bot! My Player ID is %d. Bot rng seed is %d.", game.Me.ID, seed)
+	var logger = fileLogger
+	logger.Printf("Successfully created bot! My Player ID is %d. Bot rng seed is %d, strategy %s.", game.Me.ID, seed, *strategy)
+	logger.Printf("Profile for %dx%d with %d players: %v", game.Map.Width(), game.Map.Height(), game.NumPlayers(), profile)
+	logger.Printf("Params: %s", params)
+
+	// scan the board with a width/8 window to find the rich areas while the clock is not running
+	analysis := gameAI.Analyze(initStart.Add(time.Duration(params.InitAnalysisMillis) * time.Millisecond))
+	logger.Printf("Map analysis took %s, complete=%t, %d clusters, %d dropoff sites", time.Since(initStart), analysis.Complete, len(analysis.Clusters), len(analysis.Sites))
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			exitWith(err)
+		}
+		defer f.Close()
+		gameAI.SetTracer(logic.NewTracer(f))
+	}
 	gracefulExit(fileLogger)
-	game.Ready("jm")
-	maxTurn, _ := config.GetInt(gameconfig.MaxTurns)
+	if !*dryRun {
+		game.Ready(*name)
+	}
 	for {
-		game.UpdateFrame()
-		var me = game.Me
-		var gameMap = game.Map
-		var ships = me.Ships
-		var commands = []hlt.Command{}
-		var moveAI = logic.NewMoveAI(gameAI, gameMap, me)
-		var convertAI = logic.NewConvertAI(gameAI)
-		if com := convertAI.DeterminePossibleDropOff(ships); com != nil {
-			commands = append(commands, com)
+		// returning instead of exiting lets the deferred closes flush the record and trace files
+		if err := game.UpdateFrame(); err != nil {
+			if err != io.EOF {
+				logger.Errorf("reading the frame: %v", err)
+			}
+			logger.Printf("Input connection from server closed. Exiting...")
+			fileLogger.Close()
+			return
 		}
-		for i := range ships {
-			var ship = ships[i]
-			if convertAI.IsCurrentDropoff(ship) {
-				continue
+		deadline := gameAI.NewDeadline()
+		gameAI.Update(deadline)
+		var me = game.Me
+		var moveAI = logic.NewMoveAI(gameAI, game.Map, me)
+		var commands = gameAI.PlanTurn(moveAI, logic.NewConvertAI(gameAI))
+		if *renderMode != "" {
+			opts := render.Options{ANSI: *renderMode == "ansi", Commands: commands, Legend: game.TurnNumber == 1}
+			for _, cell := range moveAI.FuturePos {
+				opts.Claimed = append(opts.Claimed, cell.Pos)
+			}
+			if *renderCrop > 0 {
+				opts.Center, opts.Radius = me.Shipyard.E.Pos, *renderCrop
+			}
+			if *dryRun {
+				fmt.Print(render.ASCII(game, opts))
+			} else if board := logger.Sub("render"); board.Enabled(log.Debug) {
+				board.Debug("board\n"+render.ASCII(game, opts), nil)
 			}
-			commands = append(commands, moveAI.Move(ship))
 		}
-		var shipCost, _ = config.GetInt(gameconfig.ShipCost)
-		if len(ships) < maxShipCount && me.Halite >= (shipCost) && !gameMap.AtEntity(me.Shipyard.E).IsOccupied() && (maxTurn-game.TurnNumber) >= 100 {
-			commands = append(commands, hlt.SpawnShip{})
-			if (len(ships)+1) >= maxShipCount && maxShipCount > 6 {
-				maxShipCount--
+		if *snapDir != "" && *snapEvery > 0 && game.TurnNumber%*snapEvery == 0 {
+			if err := writeSnapshot(gameAI, game, commands, moveAI.FuturePos, *snapDir, *snapField, *snapFormat); err != nil {
+				logger.Errorf("snapshot: %v", err)
 			}
 		}
-		if math.Mod(float64(game.TurnNumber), 100.0) == 0 {
-			maxShipCount--
+		if err := gameAI.Tracer().End(deadline.Elapsed()); err != nil {
+			logger.Errorf("trace: %v", err)
+		}
+		if deadline.OverSoft() {
+			logger.Warnf("turn %d took %s, over the soft budget of %s", game.TurnNumber, deadline.Elapsed(), deadline.Soft())
+		}
+		if *dryRun {
+			printDecisions(gameAI, game, commands)
+			continue
 		}
 		game.EndTurn(commands)
 	}
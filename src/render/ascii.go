@@ -0,0 +1,147 @@
+package render
+
+import (
+	"fmt"
+	"hlt"
+	"strings"
+)
+
+// halite shades from empty to full, a cell at or above maxShade halite gets the last one
+const (
+	shades   = " .:-=+*#%@"
+	maxShade = 1000
+)
+
+// ANSI colours per player, cycling past four players
+var playerColors = []string{"\x1b[31m", "\x1b[32m", "\x1b[34m", "\x1b[33m"}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiReverse = "\x1b[7m"
+	ansiDim     = "\x1b[2m"
+)
+
+// Options - What to draw on top of the board and which part of it
+type Options struct {
+	ANSI     bool            // colour players and highlight claimed cells with escape codes
+	Center   *hlt.Position   // crop around this position, nil draws the whole board
+	Radius   int             // cells shown on each side of Center
+	Commands []hlt.Command   // this turn's commands, moving ships are drawn as arrows
+	Claimed  []*hlt.Position // cells claimed for next turn, e.g. MoveAI.FuturePos
+	Legend   bool            // add a line explaining the glyphs
+}
+
+// ASCII - Draws the board one character per cell. Halite is shaded with " .:-=+*#%@", ships show the digit of
+// their player, or an arrow for ships moving this turn, shipyards are A-D and dropoffs a-d by player, and
+// claimed cells without a ship are x
+func ASCII(game *hlt.Game, opts Options) string {
+	gMap := game.Map
+	w, h := gMap.Width(), gMap.Height()
+	x0, y0, cols, rows := 0, 0, w, h
+	if opts.Center != nil {
+		side := 2*opts.Radius + 1
+		if side < w {
+			x0, cols = opts.Center.X()-opts.Radius, side
+		}
+		if side < h {
+			y0, rows = opts.Center.Y()-opts.Radius, side
+		}
+	}
+
+	moves := make(map[int]*hlt.Direction)
+	for _, com := range opts.Commands {
+		if m, ok := com.(*hlt.Move); ok {
+			moves[m.ShipID()] = m.Direction()
+		}
+	}
+	claimed := make(map[string]bool)
+	for _, pos := range opts.Claimed {
+		claimed[pos.String()] = true
+	}
+	owner := make(map[string]int)
+	glyphs := make(map[string]byte)
+	for _, p := range game.Players() {
+		owner[p.Shipyard.E.Pos.String()] = p.ID
+		glyphs[p.Shipyard.E.Pos.String()] = 'A' + byte(p.ID)
+		for _, d := range p.Dropoffs {
+			owner[d.E.Pos.String()] = p.ID
+			glyphs[d.E.Pos.String()] = 'a' + byte(p.ID)
+		}
+	}
+	for _, p := range game.Players() {
+		for _, s := range p.Ships {
+			key := s.E.Pos.String()
+			owner[key] = p.ID
+			glyphs[key] = '0' + byte(p.ID)
+			if d, ok := moves[s.E.ID()]; ok && p.ID == game.Me.ID {
+				glyphs[key] = arrow(d, glyphs[key])
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "turn %d, %dx%d", game.TurnNumber, w, h)
+	if cols != w || rows != h {
+		fmt.Fprintf(&b, " around %s", opts.Center)
+	}
+	b.WriteByte('\n')
+	for r := 0; r < rows; r++ {
+		y := ((y0+r)%h + h) % h
+		for c := 0; c < cols; c++ {
+			x := ((x0+c)%w + w) % w
+			cell := gMap.Cells[y][x]
+			key := cell.Pos.String()
+			glyph, entity := glyphs[key]
+			if !entity {
+				glyph = shade(cell.Halite)
+				if claimed[key] {
+					glyph = 'x'
+				}
+			}
+			if !opts.ANSI {
+				b.WriteByte(glyph)
+				continue
+			}
+			switch {
+			case entity:
+				color := playerColors[owner[key]%len(playerColors)]
+				if claimed[key] {
+					color += ansiReverse
+				}
+				b.WriteString(ansiBold + color + string(glyph) + ansiReset)
+			case claimed[key]:
+				b.WriteString(ansiReverse + string(glyph) + ansiReset)
+			default:
+				b.WriteString(ansiDim + string(glyph) + ansiReset)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	if opts.Legend {
+		b.WriteString("halite \"" + shades + "\", ships 0-3, moving ^v<>, shipyards A-D, dropoffs a-d, claimed x\n")
+	}
+	return b.String()
+}
+
+func shade(halite int) byte {
+	i := halite * len(shades) / (maxShade + 1)
+	if i >= len(shades) {
+		i = len(shades) - 1
+	}
+	return shades[i]
+}
+
+func arrow(d *hlt.Direction, still byte) byte {
+	switch d.String() {
+	case "n":
+		return '^'
+	case "s":
+		return 'v'
+	case "e":
+		return '>'
+	case "w":
+		return '<'
+	}
+	return still
+}
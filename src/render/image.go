@@ -0,0 +1,253 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"hlt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// heatStops - dark to bright colour scale for halite and derived fields
+var heatStops = []color.RGBA{
+	{0, 0, 4, 255},
+	{87, 16, 110, 255},
+	{188, 55, 84, 255},
+	{249, 142, 9, 255},
+	{252, 255, 164, 255},
+}
+
+// player colours in images, cycling past four players
+var playerRGB = []color.RGBA{
+	{230, 57, 70, 255},
+	{42, 157, 143, 255},
+	{69, 123, 157, 255},
+	{233, 196, 106, 255},
+}
+
+var white = color.RGBA{255, 255, 255, 255}
+
+// ImageOptions - What to draw in a PNG or SVG snapshot
+type ImageOptions struct {
+	CellSize int             // pixels per cell, 8 when zero
+	Field    [][]float64     // heatmap under the entities indexed [y][x], the board halite when nil
+	Min, Max float64         // ends of the colour scale, taken from the field when both are zero
+	Commands []hlt.Command   // this turn's commands, moving ships get a line in their direction
+	Claimed  []*hlt.Position // cells claimed for next turn, outlined
+}
+
+type markKind int
+
+const (
+	markShip = markKind(iota)
+	markShipyard
+	markDropoff
+)
+
+// mark - entity drawn on top of the heatmap
+type mark struct {
+	x, y   int
+	kind   markKind
+	player int
+	dir    *hlt.Direction // planned move of our ships, nil when staying or unknown
+}
+
+type snapshot struct {
+	game    *hlt.Game
+	cell    int
+	field   [][]float64
+	min     float64
+	max     float64
+	marks   []mark
+	claimed []*hlt.Position
+}
+
+func newSnapshot(game *hlt.Game, opts ImageOptions) *snapshot {
+	s := &snapshot{game: game, cell: opts.CellSize, field: opts.Field, min: opts.Min, max: opts.Max, claimed: opts.Claimed}
+	if s.cell <= 0 {
+		s.cell = 8
+	}
+	if s.field == nil {
+		s.field = make([][]float64, game.Map.Height())
+		for y, row := range game.Map.Cells {
+			s.field[y] = make([]float64, len(row))
+			for x, c := range row {
+				s.field[y][x] = float64(c.Halite)
+			}
+		}
+		if s.min == 0 && s.max == 0 {
+			s.max = maxShade
+		}
+	}
+	if s.min == 0 && s.max == 0 {
+		s.min, s.max = math.Inf(1), math.Inf(-1)
+		for _, row := range s.field {
+			for _, v := range row {
+				s.min, s.max = math.Min(s.min, v), math.Max(s.max, v)
+			}
+		}
+	}
+
+	moves := make(map[int]*hlt.Direction)
+	for _, com := range opts.Commands {
+		if m, ok := com.(*hlt.Move); ok && !m.Direction().Equals(hlt.Still()) {
+			moves[m.ShipID()] = m.Direction()
+		}
+	}
+	for _, p := range game.Players() {
+		pos := p.Shipyard.E.Pos
+		s.marks = append(s.marks, mark{pos.X(), pos.Y(), markShipyard, p.ID, nil})
+		for _, d := range p.Dropoffs {
+			s.marks = append(s.marks, mark{d.E.Pos.X(), d.E.Pos.Y(), markDropoff, p.ID, nil})
+		}
+	}
+	for _, p := range game.Players() {
+		for _, ship := range p.Ships {
+			m := mark{ship.E.Pos.X(), ship.E.Pos.Y(), markShip, p.ID, nil}
+			if p.ID == game.Me.ID {
+				m.dir = moves[ship.E.ID()]
+			}
+			s.marks = append(s.marks, m)
+		}
+	}
+	return s
+}
+
+// heat - colour of a field value on the scale
+func (s *snapshot) heat(v float64) color.RGBA {
+	t := 0.0
+	if s.max > s.min {
+		t = math.Max(0, math.Min(1, (v-s.min)/(s.max-s.min)))
+	}
+	f := t * float64(len(heatStops)-1)
+	i := int(f)
+	if i >= len(heatStops)-1 {
+		return heatStops[len(heatStops)-1]
+	}
+	a, b, frac := heatStops[i], heatStops[i+1], f-float64(i)
+	mix := func(p, q uint8) uint8 {
+		return uint8(float64(p) + frac*(float64(q)-float64(p)))
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+func dirOffset(d *hlt.Direction) (int, int) {
+	switch {
+	case d.Equals(hlt.North()):
+		return 0, -1
+	case d.Equals(hlt.South()):
+		return 0, 1
+	case d.Equals(hlt.East()):
+		return 1, 0
+	case d.Equals(hlt.West()):
+		return -1, 0
+	}
+	return 0, 0
+}
+
+// Image - Draws the board as an image: the field as a heatmap, shipyards as outlined squares, dropoffs as small
+// squares and ships as dots in their player's colour
+func Image(game *hlt.Game, opts ImageOptions) *image.RGBA {
+	s := newSnapshot(game, opts)
+	cs := s.cell
+	img := image.NewRGBA(image.Rect(0, 0, game.Map.Width()*cs, game.Map.Height()*cs))
+	fill := func(x0, y0, x1, y1 int, c color.RGBA) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	for y, row := range s.field {
+		for x, v := range row {
+			fill(x*cs, y*cs, (x+1)*cs, (y+1)*cs, s.heat(v))
+		}
+	}
+	for _, pos := range s.claimed {
+		x0, y0 := pos.X()*cs, pos.Y()*cs
+		fill(x0, y0, x0+cs, y0+1, white)
+		fill(x0, y0+cs-1, x0+cs, y0+cs, white)
+		fill(x0, y0, x0+1, y0+cs, white)
+		fill(x0+cs-1, y0, x0+cs, y0+cs, white)
+	}
+	for _, m := range s.marks {
+		c := playerRGB[m.player%len(playerRGB)]
+		x0, y0 := m.x*cs, m.y*cs
+		switch m.kind {
+		case markShipyard:
+			fill(x0, y0, x0+cs, y0+cs, c)
+			fill(x0+cs/4, y0+cs/4, x0+cs-cs/4, y0+cs-cs/4, white)
+		case markDropoff:
+			fill(x0+cs/4, y0+cs/4, x0+cs-cs/4, y0+cs-cs/4, c)
+		case markShip:
+			r := float64(cs) * 0.35
+			center := float64(cs-1) / 2
+			for dy := 0; dy < cs; dy++ {
+				for dx := 0; dx < cs; dx++ {
+					if math.Hypot(float64(dx)-center, float64(dy)-center) <= r {
+						img.SetRGBA(x0+dx, y0+dy, c)
+					}
+				}
+			}
+			if m.dir != nil {
+				ox, oy := dirOffset(m.dir)
+				for i := 0; i <= cs/2; i++ {
+					img.SetRGBA(x0+cs/2+ox*i, y0+cs/2+oy*i, white)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG - Writes the board snapshot as a PNG
+func WritePNG(w io.Writer, game *hlt.Game, opts ImageOptions) error {
+	return png.Encode(w, Image(game, opts))
+}
+
+// WriteSVG - Writes the board snapshot as an SVG, drawn the same way as Image
+func WriteSVG(w io.Writer, game *hlt.Game, opts ImageOptions) error {
+	s := newSnapshot(game, opts)
+	cs := s.cell
+	hex := func(c color.RGBA) string {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" shape-rendering=\"crispEdges\">\n",
+		game.Map.Width()*cs, game.Map.Height()*cs)
+	fmt.Fprintf(bw, "<title>turn %d</title>\n", game.TurnNumber)
+	for y, row := range s.field {
+		for x, v := range row {
+			fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"><title>%d,%d: %g</title></rect>\n",
+				x*cs, y*cs, cs, cs, hex(s.heat(v)), x, y, v)
+		}
+	}
+	for _, pos := range s.claimed {
+		fmt.Fprintf(bw, "<rect x=\"%g\" y=\"%g\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"white\"/>\n",
+			float64(pos.X()*cs)+0.5, float64(pos.Y()*cs)+0.5, cs-1, cs-1)
+	}
+	for _, m := range s.marks {
+		c := hex(playerRGB[m.player%len(playerRGB)])
+		x0, y0 := m.x*cs, m.y*cs
+		switch m.kind {
+		case markShipyard:
+			fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"white\" stroke=\"%s\" stroke-width=\"%d\"/>\n",
+				x0+cs/8, y0+cs/8, cs-cs/4, cs-cs/4, c, cs/4)
+		case markDropoff:
+			fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", x0+cs/4, y0+cs/4, cs-cs/2, cs-cs/2, c)
+		case markShip:
+			cx, cy := float64(x0)+float64(cs)/2, float64(y0)+float64(cs)/2
+			fmt.Fprintf(bw, "<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"%s\"/>\n", cx, cy, float64(cs)*0.35, c)
+			if m.dir != nil {
+				ox, oy := dirOffset(m.dir)
+				fmt.Fprintf(bw, "<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"white\"/>\n",
+					cx, cy, cx+float64(ox*cs)/2, cy+float64(oy*cs)/2)
+			}
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
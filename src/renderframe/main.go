@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hlt"
+	"hlt/input"
+	"os"
+	"render"
+	"replay"
+	"scenario"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var (
+		replayFile = flag.String("replay", "", "engine replay to draw a frame of, plain JSON or gzip")
+		recording  = flag.String("recording", "", "game recorded by the bot with -record-input to draw a turn of")
+		turn       = flag.Int("turn", 1, "frame of the replay or turn of the recording to draw")
+		center     = flag.String("center", "", "x,y to crop the board around, the whole board is drawn without it")
+		radius     = flag.Int("radius", 8, "cells shown on each side of -center")
+		ansi       = flag.Bool("ansi", false, "colour players with ANSI escape codes")
+		legend     = flag.Bool("legend", false, "add a line explaining the glyphs")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s -replay game.hlt | -recording game.txt [flags]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if (*replayFile == "") == (*recording == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *radius < 0 {
+		fatal(fmt.Errorf("-radius must not be negative"))
+	}
+
+	var game *hlt.Game
+	var err error
+	if *replayFile != "" {
+		game, err = fromReplay(*replayFile, *turn)
+	} else {
+		game, err = fromRecording(*recording, *turn)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	opts := render.Options{ANSI: *ansi, Legend: *legend}
+	if *center != "" {
+		if opts.Center, err = parsePosition(*center, game.Map); err != nil {
+			fatal(err)
+		}
+		opts.Radius = *radius
+	}
+	fmt.Print(render.ASCII(game, opts))
+}
+
+// fromReplay - the board of one frame of an engine replay, rebuilt as a scenario. Ship IDs are the scenario's
+func fromReplay(path string, frame int) (*hlt.Game, error) {
+	r, err := replay.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	halite, err := r.HaliteAt(frame)
+	if err != nil {
+		return nil, err
+	}
+	dropoffs, err := r.DropoffsAt(frame)
+	if err != nil {
+		return nil, err
+	}
+	s := scenario.New(r.Production.Width, r.Production.Height).Players(len(r.Players)).Turn(frame)
+	for y, row := range halite {
+		for x, h := range row {
+			s.Halite(x, y, h)
+		}
+	}
+	f := r.Frames[frame]
+	for _, p := range r.Players {
+		s.Shipyard(p.ID, p.Shipyard.X, p.Shipyard.Y)
+		s.Bank(p.ID, f.Energy[strconv.Itoa(p.ID)])
+		for _, d := range dropoffs[p.ID] {
+			s.Dropoff(p.ID, d.X, d.Y)
+		}
+		for _, ship := range f.Entities[strconv.Itoa(p.ID)] {
+			s.Ship(p.ID, ship.X, ship.Y, ship.Energy)
+		}
+	}
+	return s.Game()
+}
+
+// fromRecording - plays the bot's recorded input forward to the turn
+func fromRecording(path string, turn int) (*hlt.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if turn < 1 {
+		return nil, fmt.Errorf("%s: turns start at 1", path)
+	}
+	input.SetSource(f)
+	game := hlt.NewGame()
+	if game == nil {
+		return nil, fmt.Errorf("%s: no game in input", path)
+	}
+	for game.TurnNumber < turn {
+		if err := game.UpdateFrame(); err != nil {
+			return nil, fmt.Errorf("%s: no turn %d, the recording ends after turn %d", path, turn, game.TurnNumber)
+		}
+	}
+	return game, nil
+}
+
+// parsePosition - reads x,y, which has to be on the map
+func parsePosition(value string, gameMap *hlt.GameMap) (*hlt.Position, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 2 {
+		x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
+		y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if errX == nil && errY == nil && x >= 0 && y >= 0 && x < gameMap.Width() && y < gameMap.Height() {
+			return hlt.NewPosition(x, y), nil
+		}
+	}
+	return nil, fmt.Errorf("-center %q is not x,y on the %dx%d map", value, gameMap.Width(), gameMap.Height())
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "renderframe:", err)
+	os.Exit(1)
+}
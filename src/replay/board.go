@@ -0,0 +1,50 @@
+package replay
+
+import "fmt"
+
+// HaliteAt - Returns the halite of every cell at the start of the frame: the production map with the cell updates
+// of every earlier frame applied
+func (r *Replay) HaliteAt(frame int) ([][]int, error) {
+	if err := r.checkFrame(frame); err != nil {
+		return nil, err
+	}
+	halite := make([][]int, r.Production.Height)
+	for y := range halite {
+		halite[y] = make([]int, r.Production.Width)
+		for x := range halite[y] {
+			halite[y][x] = r.Production.Grid[y][x].Energy
+		}
+	}
+	for _, f := range r.Frames[:frame] {
+		for _, c := range f.Cells {
+			halite[c.Y][c.X] = c.Energy
+		}
+	}
+	return halite, nil
+}
+
+// DropoffsAt - Returns the dropoffs standing at the start of the frame by player, the ones built on earlier frames
+func (r *Replay) DropoffsAt(frame int) (map[int][]Location, error) {
+	if err := r.checkFrame(frame); err != nil {
+		return nil, err
+	}
+	dropoffs := make(map[int][]Location)
+	for _, f := range r.Frames[:frame] {
+		for _, e := range f.Events {
+			if e.Type == "construct" {
+				dropoffs[e.OwnerID] = append(dropoffs[e.OwnerID], e.Location)
+			}
+		}
+	}
+	return dropoffs, nil
+}
+
+func (r *Replay) checkFrame(frame int) error {
+	if err := r.check(); err != nil {
+		return fmt.Errorf("replay: %v", err)
+	}
+	if frame < 0 || frame >= len(r.Frames) {
+		return fmt.Errorf("replay: frame %d, the replay has frames 0 to %d", frame, len(r.Frames)-1)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Replay - The parts of a Halite III replay file the stats are worked out from
+type Replay struct {
+	Constants  map[string]interface{} `json:"GAME_CONSTANTS"`
+	Statistics GameStatistics         `json:"game_statistics"`
+	Production ProductionMap          `json:"production_map"`
+	Players    []Player               `json:"players"`
+	Frames     []Frame                `json:"full_frames"`
+}
+
+// GameStatistics - End of game summary written by the engine
+type GameStatistics struct {
+	NumberTurns int                `json:"number_turns"`
+	Players     []PlayerStatistics `json:"player_statistics"`
+}
+
+// PlayerStatistics - Engine summary for one player
+type PlayerStatistics struct {
+	PlayerID        int `json:"player_id"`
+	Rank            int `json:"rank"`
+	FinalProduction int `json:"final_production"`
+}
+
+// ProductionMap - Halite on the board at the start of the game
+type ProductionMap struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Grid   [][]struct {
+		Energy int `json:"energy"`
+	} `json:"grid"`
+}
+
+// Location - Cell on the board
+type Location struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Player - A player as listed at the start of the replay
+type Player struct {
+	ID       int      `json:"player_id"`
+	Name     string   `json:"name"`
+	Shipyard Location `json:"factory_location"`
+}
+
+// ShipState - A ship as seen at the start of a frame
+type ShipState struct {
+	X        int  `json:"x"`
+	Y        int  `json:"y"`
+	Energy   int  `json:"energy"`
+	Inspired bool `json:"is_inspired"`
+}
+
+// Move - A command a player sent in a frame
+type Move struct {
+	Type      string `json:"type"`
+	ID        int    `json:"id"`
+	Direction string `json:"direction"`
+}
+
+// Event - Something the engine did while running a frame
+type Event struct {
+	Type     string   `json:"type"`
+	Location Location `json:"location"`
+	OwnerID  int      `json:"owner_id"`
+	ID       int      `json:"id"`
+	Ships    []int    `json:"ships"`
+}
+
+// CellUpdate - Cell whose halite changed in a frame
+type CellUpdate struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Energy int `json:"production"`
+}
+
+// Frame - One turn of the replay. Entities are the ships at the start of the turn, keyed by player and then
+// ship ID, and the moves, events and cell updates are what happened during it
+type Frame struct {
+	Entities  map[string]map[string]ShipState `json:"entities"`
+	Moves     map[string][]Move               `json:"moves"`
+	Events    []Event                         `json:"events"`
+	Cells     []CellUpdate                    `json:"cells"`
+	Deposited map[string]int                  `json:"deposited"`
+	Energy    map[string]int                  `json:"energy"`
+}
+
+// Load - Reads a replay file. Plain JSON and gzip are read directly, the zstd files the engine writes by default
+// have to be decompressed first or recorded with --no-compression
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(4)
+	var r io.Reader = br
+	switch {
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, fmt.Errorf("replay: %s is zstd compressed, decompress it with `zstd -d` or run the engine with --no-compression", path)
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("replay: %s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	var rep Replay
+	if err := json.NewDecoder(r).Decode(&rep); err != nil {
+		return nil, fmt.Errorf("replay: %s: %v", path, err)
+	}
+	if len(rep.Players) == 0 {
+		return nil, fmt.Errorf("replay: %s: no players", path)
+	}
+	if err := rep.check(); err != nil {
+		return nil, fmt.Errorf("replay: %s: %v", path, err)
+	}
+	return &rep, nil
+}
+
+// check - the stats index the board by the production map, so every ship and cell update has to be on it
+func (r *Replay) check() error {
+	w, h := r.Production.Width, r.Production.Height
+	if len(r.Frames) == 0 {
+		return fmt.Errorf("no frames")
+	}
+	if w <= 0 || h <= 0 || len(r.Production.Grid) != h {
+		return fmt.Errorf("production map is %dx%d with %d rows", w, h, len(r.Production.Grid))
+	}
+	for y, row := range r.Production.Grid {
+		if len(row) != w {
+			return fmt.Errorf("production map row %d has %d cells, want %d", y, len(row), w)
+		}
+	}
+	inside := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h
+	}
+	for t, f := range r.Frames {
+		for pkey, ships := range f.Entities {
+			for skey, s := range ships {
+				if !inside(s.X, s.Y) {
+					return fmt.Errorf("frame %d: ship %s of player %s at %d,%d is off the %dx%d map", t, skey, pkey, s.X, s.Y, w, h)
+				}
+			}
+		}
+		for _, c := range f.Cells {
+			if !inside(c.X, c.Y) {
+				return fmt.Errorf("frame %d: cell update at %d,%d is off the %dx%d map", t, c.X, c.Y, w, h)
+			}
+		}
+	}
+	return nil
+}
+
+// ConstantInt - Returns an integer game constant, or def when the replay does not have it
+func (r *Replay) ConstantInt(name string, def int) int {
+	if v, ok := r.Constants[name].(float64); ok {
+		return int(v)
+	}
+	return def
+}
+
+// Name - Returns the name of the player
+func (r *Replay) Name(player int) string {
+	for _, p := range r.Players {
+		if p.ID == player {
+			return p.Name
+		}
+	}
+	return fmt.Sprintf("player %d", player)
+}
+
+// Rank - Returns the final rank of the player, 0 when the replay has no statistics
+func (r *Replay) Rank(player int) int {
+	for _, p := range r.Statistics.Players {
+		if p.PlayerID == player {
+			return p.Rank
+		}
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+package replay
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ShipStats - What one ship did over its life
+type ShipStats struct {
+	Player    int
+	ID        int
+	Born      int // frame the ship was first seen
+	Died      int // frame the ship was last seen
+	Mined     int // halite picked up, inspiration bonus included
+	Deposited int // halite dropped off at a dock
+	Burned    int // halite spent on moving
+	Lost      int // cargo lost when the ship was destroyed in a collision
+	Idle      int // turns the ship neither moved nor mined
+	Trips     int // completed trips out from a dock and back
+	TripTurns int // turns spent on completed trips
+	Converted bool
+}
+
+// AverageTrip - Mean length of the ship's completed trips in turns
+func (s *ShipStats) AverageTrip() float64 {
+	if s.Trips == 0 {
+		return 0
+	}
+	return float64(s.TripTurns) / float64(s.Trips)
+}
+
+// PlayerStats - Totals for one player, summed over its ships
+type PlayerStats struct {
+	Player       int
+	Name         string
+	Rank         int
+	FinalHalite  int
+	ShipsBuilt   int
+	Dropoffs     int
+	FirstDropoff int // frame of the first dropoff, -1 if it never built one
+	Mined        int
+	Deposited    int
+	Burned       int
+	Lost         int
+	Idle         int
+	Trips        int
+	TripTurns    int
+	Ships        []*ShipStats
+}
+
+// AverageTrip - Mean length of the player's completed trips in turns
+func (p *PlayerStats) AverageTrip() float64 {
+	if p.Trips == 0 {
+		return 0
+	}
+	return float64(p.TripTurns) / float64(p.Trips)
+}
+
+// tracked - ship state carried between frames
+type tracked struct {
+	stats  *ShipStats
+	out    int  // frame the current trip started
+	onTrip bool // left a dock and has not dropped off since
+}
+
+// Stats - Works out per player and per ship statistics by following every ship from frame to frame. Cargo going
+// up on a turn the ship stayed still is mined, a move costs the halite of the cell it left divided by
+// MOVE_COST_RATIO and cargo disappearing on a dock is deposited. A ship that vanished without becoming a dropoff
+// sank in a collision: its cargo is deposited when that happened on one of its player's docks, since the engine
+// credits the dock's owner, and lost anywhere else. Replays whose ships are off the production map are an error
+func Stats(r *Replay) ([]*PlayerStats, error) {
+	if err := r.check(); err != nil {
+		return nil, fmt.Errorf("replay: %v", err)
+	}
+	moveCost := r.ConstantInt("MOVE_COST_RATIO", 10)
+	w, h := r.Production.Width, r.Production.Height
+	halite := make([][]int, h)
+	for y := range halite {
+		halite[y] = make([]int, w)
+		for x := range halite[y] {
+			halite[y][x] = r.Production.Grid[y][x].Energy
+		}
+	}
+	players := make(map[int]*PlayerStats)
+	docks := make(map[int]map[Location]bool)
+	for _, p := range r.Players {
+		players[p.ID] = &PlayerStats{Player: p.ID, Name: p.Name, Rank: r.Rank(p.ID), FirstDropoff: -1}
+		docks[p.ID] = map[Location]bool{p.Shipyard: true}
+	}
+	ships := make(map[int]*tracked)
+
+	for t, frame := range r.Frames {
+		for pkey, entities := range frame.Entities {
+			pid, _ := strconv.Atoi(pkey)
+			for skey := range entities {
+				id, _ := strconv.Atoi(skey)
+				if _, ok := ships[id]; !ok {
+					st := &ShipStats{Player: pid, ID: id, Born: t, Died: t}
+					ships[id] = &tracked{stats: st}
+					if p, ok := players[pid]; ok {
+						p.Ships = append(p.Ships, st)
+					}
+				}
+				ships[id].stats.Died = t
+			}
+		}
+		converted := make(map[int]bool)
+		for _, e := range frame.Events {
+			p, ok := players[e.OwnerID]
+			switch {
+			case e.Type == "spawn" && ok:
+				p.ShipsBuilt++
+			case e.Type == "construct" && ok:
+				p.Dropoffs++
+				if p.FirstDropoff < 0 {
+					p.FirstDropoff = t
+				}
+				docks[e.OwnerID][e.Location] = true
+				converted[e.ID] = true
+			}
+		}
+		if t+1 < len(r.Frames) {
+			next := r.Frames[t+1]
+			for pkey, entities := range frame.Entities {
+				pid, _ := strconv.Atoi(pkey)
+				moved := make(map[int]string)
+				for _, m := range frame.Moves[pkey] {
+					if m.Type == "m" && m.Direction != "o" {
+						moved[m.ID] = m.Direction
+					}
+				}
+				for skey, s := range entities {
+					id, _ := strconv.Atoi(skey)
+					tr := ships[id]
+					st := tr.stats
+					here := Location{s.X, s.Y}
+					dir, isMove := moved[id]
+					burn := 0
+					if isMove {
+						burn = halite[s.Y][s.X] / moveCost
+					}
+					after, alive := next.Entities[pkey][skey]
+					switch {
+					case converted[id]:
+						// the engine credits the cargo of a ship that becomes a dropoff to its player
+						st.Converted = true
+						st.Deposited += s.Energy
+					case !alive:
+						st.Burned += burn
+						if docks[pid][step(here, dir, w, h)] {
+							st.Deposited += s.Energy - burn
+						} else {
+							st.Lost += s.Energy - burn
+						}
+					default:
+						there := Location{after.X, after.Y}
+						if !isMove || there == here {
+							if gain := after.Energy - s.Energy; gain > 0 {
+								st.Mined += gain
+							} else {
+								st.Idle++
+							}
+							break
+						}
+						st.Burned += burn
+						if docks[pid][here] && !tr.onTrip {
+							tr.onTrip, tr.out = true, t
+						}
+						if docks[pid][there] {
+							st.Deposited += s.Energy - burn
+							if tr.onTrip {
+								st.Trips++
+								st.TripTurns += t + 1 - tr.out
+							}
+							tr.onTrip = false
+						}
+					}
+				}
+			}
+		}
+		for _, c := range frame.Cells {
+			halite[c.Y][c.X] = c.Energy
+		}
+	}
+
+	last := r.Frames[len(r.Frames)-1]
+	result := make([]*PlayerStats, 0, len(players))
+	for _, p := range players {
+		p.FinalHalite = last.Energy[strconv.Itoa(p.Player)]
+		sort.Slice(p.Ships, func(i, j int) bool {
+			return p.Ships[i].ID < p.Ships[j].ID
+		})
+		for _, s := range p.Ships {
+			p.Mined += s.Mined
+			p.Deposited += s.Deposited
+			p.Burned += s.Burned
+			p.Lost += s.Lost
+			p.Idle += s.Idle
+			p.Trips += s.Trips
+			p.TripTurns += s.TripTurns
+		}
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Player < result[j].Player
+	})
+	return result, nil
+}
+
+// step - the cell a move in the direction ends on, the same cell for no move
+func step(from Location, dir string, w, h int) Location {
+	switch dir {
+	case "n":
+		from.Y = (from.Y + h - 1) % h
+	case "s":
+		from.Y = (from.Y + 1) % h
+	case "e":
+		from.X = (from.X + 1) % w
+	case "w":
+		from.X = (from.X + w - 1) % w
+	}
+	return from
+}
@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testdata/collisions.json is a hand written 5x3 game over four turns:
+//   - ship 0 of player 0 leaves the shipyard at 0,1, mines 25 at 1,1 and brings it back, burning 7 on the cell it
+//     mined down to 75
+//   - ship 1 of player 0 with 400 and ship 2 of player 1 with 60 crash on player 0's shipyard, which credits both
+//     cargos to player 0
+//   - ship 3 of player 0 with 300 and ship 4 of player 1 with 100 crash at 2,2, ship 3 burning 5 on the way
+func TestStats(t *testing.T) {
+	r, err := Load("testdata/collisions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Stats(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PlayerStats{
+		{Player: 0, Name: "us", Rank: 1, FinalHalite: 4478, ShipsBuilt: 1, FirstDropoff: -1,
+			Mined: 25, Deposited: 418, Burned: 12, Lost: 295, Trips: 1, TripTurns: 3},
+		{Player: 1, Name: "them", Rank: 2, FinalHalite: 5000, FirstDropoff: -1, Lost: 160},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("%d players, want %d", len(stats), len(want))
+	}
+	for i, w := range want {
+		got := *stats[i]
+		got.Ships = nil
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("player %d:\n got  %+v\n want %+v", i, got, w)
+		}
+	}
+	ships := map[int]ShipStats{
+		0: {Player: 0, ID: 0, Born: 1, Died: 4, Mined: 25, Deposited: 18, Burned: 7, Trips: 1, TripTurns: 3},
+		1: {Player: 0, ID: 1, Born: 1, Died: 1, Deposited: 400},
+		2: {Player: 1, ID: 2, Born: 1, Died: 1, Lost: 60},
+		3: {Player: 0, ID: 3, Born: 1, Died: 1, Burned: 5, Lost: 295},
+		4: {Player: 1, ID: 4, Born: 1, Died: 1, Lost: 100},
+	}
+	for _, p := range stats {
+		for _, s := range p.Ships {
+			if *s != ships[s.ID] {
+				t.Errorf("ship %d:\n got  %+v\n want %+v", s.ID, *s, ships[s.ID])
+			}
+		}
+	}
+}
+
+func TestStatsOffTheMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		spoil func(r *Replay)
+	}{
+		{"no production map", func(r *Replay) { r.Production = ProductionMap{} }},
+		{"smaller production map", func(r *Replay) {
+			r.Production.Width, r.Production.Height = 2, 2
+			r.Production.Grid = r.Production.Grid[:2]
+			for y := range r.Production.Grid {
+				r.Production.Grid[y] = r.Production.Grid[y][:2]
+			}
+		}},
+		{"short row", func(r *Replay) { r.Production.Grid[1] = r.Production.Grid[1][:3] }},
+		{"ship off the map", func(r *Replay) { r.Frames[2].Entities["0"]["0"] = ShipState{X: 7, Y: 1} }},
+		{"cell update off the map", func(r *Replay) { r.Frames[2].Cells[0].Y = 3 }},
+		{"no frames", func(r *Replay) { r.Frames = nil }},
+	}
+	for _, tt := range tests {
+		r, err := Load("testdata/collisions.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.spoil(r)
+		if _, err := Stats(r); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestHaliteAt(t *testing.T) {
+	r, err := Load("testdata/collisions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// frame 1 drops the cargo of the ships that crashed at 2,2 onto it, frame 2 mines 1,1 down to 75
+	tests := []struct {
+		frame  int
+		x, y   int
+		halite int
+	}{
+		{0, 2, 2, 300},
+		{1, 2, 2, 300},
+		{2, 2, 2, 695},
+		{2, 1, 1, 100},
+		{3, 1, 1, 75},
+		{4, 1, 1, 75},
+	}
+	for _, tt := range tests {
+		halite, err := r.HaliteAt(tt.frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := halite[tt.y][tt.x]; got != tt.halite {
+			t.Errorf("frame %d: halite at %d,%d is %d, want %d", tt.frame, tt.x, tt.y, got, tt.halite)
+		}
+	}
+	if _, err := r.HaliteAt(len(r.Frames)); err == nil {
+		t.Error("no error for a frame past the end")
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"replay"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+var playerColumns = []string{"replay", "player", "name", "rank", "final_halite", "ships_built", "dropoffs", "first_dropoff",
+	"mined", "deposited", "burned", "lost", "idle_turns", "trips", "avg_trip"}
+
+var shipColumns = []string{"replay", "player", "name", "ship", "born", "died", "mined", "deposited", "burned", "lost",
+	"idle_turns", "trips", "avg_trip", "converted"}
+
+// byName - totals of one player name over several replays
+type byName struct {
+	name    string
+	games   int
+	rankSum int
+	wins    int
+	totals  replay.PlayerStats
+	dropped int // games with at least one dropoff, for the mean first dropoff turn
+}
+
+func main() {
+	var (
+		csvFile  = flag.String("csv", "", "also write the player rows to this CSV file")
+		shipsCSV = flag.String("ships-csv", "", "write a row per ship to this CSV file")
+		ships    = flag.Bool("ships", false, "print a table row per ship as well")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] replay.hlt...\n\nReplays have to be plain JSON or gzip, run the engine with --no-compression or decompress them with zstd -d first.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var playerRows, shipRows [][]string
+	names := make(map[string]*byName)
+	for _, path := range flag.Args() {
+		rep, err := replay.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		file := filepath.Base(path)
+		stats, err := replay.Stats(rep)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, p := range stats {
+			playerRows = append(playerRows, []string{file, strconv.Itoa(p.Player), p.Name, strconv.Itoa(p.Rank),
+				strconv.Itoa(p.FinalHalite), strconv.Itoa(p.ShipsBuilt), strconv.Itoa(p.Dropoffs), strconv.Itoa(p.FirstDropoff),
+				strconv.Itoa(p.Mined), strconv.Itoa(p.Deposited), strconv.Itoa(p.Burned), strconv.Itoa(p.Lost),
+				strconv.Itoa(p.Idle), strconv.Itoa(p.Trips), strconv.FormatFloat(p.AverageTrip(), 'f', 1, 64)})
+			for _, s := range p.Ships {
+				shipRows = append(shipRows, []string{file, strconv.Itoa(p.Player), p.Name, strconv.Itoa(s.ID),
+					strconv.Itoa(s.Born), strconv.Itoa(s.Died), strconv.Itoa(s.Mined), strconv.Itoa(s.Deposited),
+					strconv.Itoa(s.Burned), strconv.Itoa(s.Lost), strconv.Itoa(s.Idle), strconv.Itoa(s.Trips),
+					strconv.FormatFloat(s.AverageTrip(), 'f', 1, 64), strconv.FormatBool(s.Converted)})
+			}
+			n, ok := names[p.Name]
+			if !ok {
+				n = &byName{name: p.Name}
+				names[p.Name] = n
+			}
+			n.add(p)
+		}
+	}
+
+	printTable(os.Stdout, playerColumns, playerRows)
+	if *ships {
+		fmt.Println()
+		printTable(os.Stdout, shipColumns, shipRows)
+	}
+	if flag.NArg() > 1 {
+		fmt.Println()
+		printSummary(os.Stdout, names)
+	}
+	if *csvFile != "" {
+		if err := writeCSV(*csvFile, playerColumns, playerRows); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *shipsCSV != "" {
+		if err := writeCSV(*shipsCSV, shipColumns, shipRows); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func (n *byName) add(p *replay.PlayerStats) {
+	n.games++
+	n.rankSum += p.Rank
+	if p.Rank == 1 {
+		n.wins++
+	}
+	t := &n.totals
+	t.FinalHalite += p.FinalHalite
+	t.ShipsBuilt += p.ShipsBuilt
+	t.Dropoffs += p.Dropoffs
+	if p.FirstDropoff >= 0 {
+		t.FirstDropoff += p.FirstDropoff
+		n.dropped++
+	}
+	t.Mined += p.Mined
+	t.Deposited += p.Deposited
+	t.Burned += p.Burned
+	t.Lost += p.Lost
+	t.Idle += p.Idle
+	t.Trips += p.Trips
+	t.TripTurns += p.TripTurns
+}
+
+// printSummary - per game averages for every player name, to compare bots across many replays
+func printSummary(w io.Writer, names map[string]*byName) {
+	sorted := make([]*byName, 0, len(names))
+	for _, n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+	columns := []string{"name", "games", "wins", "avg_rank", "final_halite", "ships_built", "dropoffs", "first_dropoff",
+		"mined", "deposited", "burned", "lost", "idle_turns", "avg_trip"}
+	rows := make([][]string, 0, len(sorted))
+	for _, n := range sorted {
+		g := float64(n.games)
+		t := &n.totals
+		avg := func(v int) string {
+			return strconv.FormatFloat(float64(v)/g, 'f', 1, 64)
+		}
+		first := "-"
+		if n.dropped > 0 {
+			first = strconv.FormatFloat(float64(t.FirstDropoff)/float64(n.dropped), 'f', 1, 64)
+		}
+		rows = append(rows, []string{n.name, strconv.Itoa(n.games), strconv.Itoa(n.wins), avg(n.rankSum),
+			avg(t.FinalHalite), avg(t.ShipsBuilt), avg(t.Dropoffs), first, avg(t.Mined), avg(t.Deposited),
+			avg(t.Burned), avg(t.Lost), avg(t.Idle), strconv.FormatFloat(t.AverageTrip(), 'f', 1, 64)})
+	}
+	printTable(w, columns, rows)
+}
+
+func printTable(w io.Writer, columns []string, rows [][]string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for i, c := range columns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, c)
+	}
+	fmt.Fprintln(tw, "\t")
+	for _, row := range rows {
+		for i, c := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, c)
+		}
+		fmt.Fprintln(tw, "\t")
+	}
+	tw.Flush()
+}
+
+func writeCSV(path string, columns []string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Write(columns)
+	w.WriteAll(rows)
+	return w.Error()
+}
@@ -0,0 +1,348 @@
+package scenario
+
+import (
+	"bufio"
+	"fmt"
+	"hlt"
+	"io/ioutil"
+	"logic"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Expectation - One "expect" line of a fixture
+type Expectation struct {
+	Line int
+	Kind string // decision, move, command or no-command
+	Ship int    // ship the decision or move is about
+	Want string // decision name, direction or command text
+}
+
+func (e Expectation) String() string {
+	switch e.Kind {
+	case "decision", "move":
+		return fmt.Sprintf("line %d: expect %s %d %s", e.Line, e.Kind, e.Ship, e.Want)
+	}
+	return fmt.Sprintf("line %d: expect %s %s", e.Line, e.Kind, e.Want)
+}
+
+// Fixture - A scenario read from a text fixture, with the parameters to play it with and what the bot should do
+type Fixture struct {
+	*Scenario
+	Name   string
+	Params map[string]string
+	Expect []Expectation
+}
+
+// Parse - Reads a text fixture. Lines before "map" set up the game, the map rows follow and "expect" lines close
+// the fixture. # starts a comment.
+//
+//	players 2           defaults to the highest player on the map plus one, at least 2
+//	me 0
+//	turn 120
+//	bank 0 5000         halite banked by player 0
+//	constant MAX_TURNS 400
+//	param search_depth 6
+//	map
+//	Y0     .    250
+//	s0:900 120  s1
+//	.      D0+s0 Y1
+//	expect decision 0 Return
+//	expect move 0 n
+//	expect command g
+//	expect no-command c 2
+//
+// A map cell is one or more parts joined by +: a number or . for the halite on the cell, Yp for the shipyard of
+// player p, Dp for a dropoff and sp or sp:cargo for a ship. Ships get their IDs in reading order, left to right
+// and top to bottom. Decisions are what GameAI.ShipLogic returns, moves and commands come from a whole turn
+// played in ship ID order
+func Parse(text string) (*Fixture, error) {
+	type cell struct {
+		line  int
+		parts []string
+	}
+	var (
+		header  [][]string
+		lines   []int
+		rows    [][]cell
+		inMap   bool
+		fixture = &Fixture{Params: make(map[string]string)}
+	)
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case fields[0] == "expect":
+			inMap = false
+			e, err := parseExpectation(n, fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			fixture.Expect = append(fixture.Expect, e)
+		case fields[0] == "map" && len(fields) == 1:
+			inMap = true
+		case inMap:
+			row := make([]cell, len(fields))
+			for i, f := range fields {
+				row[i] = cell{n, strings.Split(f, "+")}
+			}
+			rows = append(rows, row)
+		default:
+			header = append(header, fields)
+			lines = append(lines, n)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("scenario: fixture has no map")
+	}
+	width := len(rows[0])
+	for _, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("scenario: line %d: map row has %d cells, the first one has %d", row[0].line, len(row), width)
+		}
+	}
+
+	s := New(width, len(rows))
+	fixture.Scenario = s
+	maxPlayer := 1
+	for y, row := range rows {
+		for x, c := range row {
+			for _, part := range c.parts {
+				if err := s.parseCell(x, y, part, &maxPlayer); err != nil {
+					return nil, fmt.Errorf("scenario: line %d: %v", c.line, err)
+				}
+			}
+		}
+	}
+	s.players = maxPlayer + 1
+
+	for i, fields := range header {
+		if err := fixture.parseHeader(fields); err != nil {
+			return nil, fmt.Errorf("scenario: line %d: %v", lines[i], err)
+		}
+	}
+	return fixture, nil
+}
+
+// Load - Reads a text fixture from a file, named after the file
+func Load(path string) (*Fixture, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return f, nil
+}
+
+func parseExpectation(line int, args []string) (Expectation, error) {
+	e := Expectation{Line: line}
+	if len(args) == 0 {
+		return e, fmt.Errorf("scenario: line %d: expect what?", line)
+	}
+	e.Kind = args[0]
+	switch e.Kind {
+	case "decision", "move":
+		if len(args) != 3 {
+			return e, fmt.Errorf("scenario: line %d: expected expect %s <ship> <value>", line, e.Kind)
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return e, fmt.Errorf("scenario: line %d: bad ship ID %q", line, args[1])
+		}
+		e.Ship, e.Want = id, args[2]
+	case "command", "no-command":
+		if len(args) < 2 {
+			return e, fmt.Errorf("scenario: line %d: expected expect %s <command>", line, e.Kind)
+		}
+		e.Want = strings.Join(args[1:], " ")
+	default:
+		return e, fmt.Errorf("scenario: line %d: unknown expectation %q, expected decision, move, command or no-command", line, e.Kind)
+	}
+	return e, nil
+}
+
+// parseCell - one + separated part of a map cell
+func (s *Scenario) parseCell(x, y int, part string, maxPlayer *int) error {
+	if part == "." {
+		return nil
+	}
+	if h, err := strconv.Atoi(part); err == nil {
+		s.halite[y][x] = h
+		return nil
+	}
+	if len(part) < 2 {
+		return fmt.Errorf("bad cell %q", part)
+	}
+	spec := part[1:]
+	cargo := 0
+	if part[0] == 's' {
+		if i := strings.Index(spec, ":"); i >= 0 {
+			c, err := strconv.Atoi(spec[i+1:])
+			if err != nil {
+				return fmt.Errorf("bad ship cargo in %q", part)
+			}
+			spec, cargo = spec[:i], c
+		}
+	}
+	player, err := strconv.Atoi(spec)
+	if err != nil || player < 0 {
+		return fmt.Errorf("bad player in %q", part)
+	}
+	if player > *maxPlayer {
+		*maxPlayer = player
+	}
+	switch part[0] {
+	case 'Y':
+		s.Shipyard(player, x, y)
+	case 'D':
+		s.Dropoff(player, x, y)
+	case 's':
+		s.Ship(player, x, y, cargo)
+	default:
+		return fmt.Errorf("bad cell %q", part)
+	}
+	return nil
+}
+
+func (f *Fixture) parseHeader(fields []string) error {
+	ints := func(n int) ([]int, error) {
+		if len(fields) != n+1 {
+			return nil, fmt.Errorf("%s takes %d values", fields[0], n)
+		}
+		values := make([]int, n)
+		for i := range values {
+			v, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad number %q", fields[0], fields[i+1])
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+	switch fields[0] {
+	case "players", "me", "turn":
+		v, err := ints(1)
+		if err != nil {
+			return err
+		}
+		switch fields[0] {
+		case "players":
+			f.Players(v[0])
+		case "me":
+			f.Me(v[0])
+		case "turn":
+			f.Turn(v[0])
+		}
+	case "bank":
+		v, err := ints(2)
+		if err != nil {
+			return err
+		}
+		f.Bank(v[0], v[1])
+	case "constant", "param":
+		if len(fields) != 3 {
+			return fmt.Errorf("%s takes a name and a value", fields[0])
+		}
+		if fields[0] == "constant" {
+			f.Constant(fields[1], fields[2])
+		} else {
+			f.Params[fields[1]] = fields[2]
+		}
+	default:
+		return fmt.Errorf("unknown setting %q", fields[0])
+	}
+	return nil
+}
+
+// NewParams - The default params with the profile for the fixture's board and its param lines applied on top
+func (f *Fixture) NewParams() (*logic.Params, error) {
+	params := logic.DefaultParams()
+	for name, value := range f.Params {
+		if err := params.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	params.ApplyProfile(f.width, f.players)
+	if err := params.Check(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Check - Plays the fixture and returns every expectation the bot did not meet. Decisions are asked on a World
+// of their own, so they don't change the state the turn is played from
+func (f *Fixture) Check() ([]string, error) {
+	params, err := f.NewParams()
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := f.World(params)
+	if err != nil {
+		return nil, err
+	}
+	var failures []string
+	for _, e := range f.Expect {
+		if e.Kind != "decision" {
+			continue
+		}
+		got, err := decisions.Decision(e.Ship)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", e, err))
+		} else if !strings.EqualFold(got.String(), e.Want) {
+			failures = append(failures, fmt.Sprintf("%s: got %s", e, got))
+		}
+	}
+
+	params, _ = f.NewParams()
+	world, err := f.World(params)
+	if err != nil {
+		return nil, err
+	}
+	commands := world.Turn()
+	texts := make([]string, len(commands))
+	moves := make(map[int]string)
+	for i, com := range commands {
+		texts[i] = com.CommandString()
+		if m, ok := com.(*hlt.Move); ok {
+			moves[m.ShipID()] = m.Direction().String()
+		}
+	}
+	has := func(text string) bool {
+		for _, t := range texts {
+			if t == text {
+				return true
+			}
+		}
+		return false
+	}
+	for _, e := range f.Expect {
+		switch e.Kind {
+		case "move":
+			if got, ok := moves[e.Ship]; !ok {
+				failures = append(failures, fmt.Sprintf("%s: ship %d got no move, the turn was %q", e, e.Ship, texts))
+			} else if got != e.Want {
+				failures = append(failures, fmt.Sprintf("%s: got %s", e, got))
+			}
+		case "command":
+			if !has(e.Want) {
+				failures = append(failures, fmt.Sprintf("%s: the turn was %q", e, texts))
+			}
+		case "no-command":
+			if has(e.Want) {
+				failures = append(failures, fmt.Sprintf("%s: the turn was %q", e, texts))
+			}
+		}
+	}
+	return failures, nil
+}
@@ -0,0 +1,363 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"hlt"
+	"hlt/gameconfig"
+	"hlt/input"
+	"logic"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultConstants - Game constants of a default engine run, scenarios start from these
+var DefaultConstants = map[string]string{
+	"CAPTURE_ENABLED":           "false",
+	"CAPTURE_RADIUS":            "3",
+	"DROPOFF_COST":              "4000",
+	"DROPOFF_PENALTY_RATIO":     "4",
+	"EXTRACT_RATIO":             "4",
+	"FACTOR_EXP_1":              "2.0",
+	"FACTOR_EXP_2":              "2.0",
+	"INITIAL_ENERGY":            "5000",
+	"INSPIRATION_ENABLED":       "true",
+	"INSPIRATION_RADIUS":        "4",
+	"INSPIRATION_SHIP_COUNT":    "2",
+	"INSPIRED_BONUS_MULTIPLIER": "2.0",
+	"INSPIRED_EXTRACT_RATIO":    "4",
+	"INSPIRED_MOVE_COST_RATIO":  "10",
+	"MAX_CELL_PRODUCTION":       "1000",
+	"MAX_ENERGY":                "1000",
+	"MAX_PLAYERS":               "16",
+	"MAX_TURNS":                 "400",
+	"MAX_TURN_THRESHOLD":        "64",
+	"MIN_CELL_PRODUCTION":       "900",
+	"MIN_TURNS":                 "400",
+	"MIN_TURN_THRESHOLD":        "32",
+	"MOVE_COST_RATIO":           "10",
+	"NEW_ENTITY_ENERGY_COST":    "1000",
+	"PERSISTENCE":               "0.7",
+	"SHIPS_ABOVE_FOR_CAPTURE":   "3",
+	"STRICT_ERRORS":             "false",
+}
+
+type entity struct {
+	player int
+	id     int
+	x, y   int
+	cargo  int
+}
+
+// Scenario - A game state built in code. The builder writes it out the way the engine sends a game and has the
+// hlt package parse it, so the game the bot logic sees is the same as in a real match
+type Scenario struct {
+	width, height int
+	halite        [][]int
+	players       int
+	me            int
+	turn          int
+	banked        map[int]int
+	shipyards     map[int][2]int
+	ships         []entity
+	dropoffs      []entity
+	constants     map[string]string
+	nextShip      int
+	nextDropoff   int
+}
+
+// New - Creates an empty width x height board for two players, with us as player 0, on turn 1
+func New(width, height int) *Scenario {
+	s := &Scenario{
+		width:     width,
+		height:    height,
+		halite:    make([][]int, height),
+		players:   2,
+		turn:      1,
+		banked:    make(map[int]int),
+		shipyards: make(map[int][2]int),
+		constants: make(map[string]string),
+	}
+	for y := range s.halite {
+		s.halite[y] = make([]int, width)
+	}
+	for k, v := range DefaultConstants {
+		s.constants[k] = v
+	}
+	return s
+}
+
+// Players - Sets the number of players
+func (s *Scenario) Players(n int) *Scenario {
+	s.players = n
+	return s
+}
+
+// Me - Sets the player the bot plays as
+func (s *Scenario) Me(player int) *Scenario {
+	s.me = player
+	return s
+}
+
+// Turn - Sets the turn the game is on
+func (s *Scenario) Turn(turn int) *Scenario {
+	s.turn = turn
+	return s
+}
+
+// Halite - Sets the halite of one cell
+func (s *Scenario) Halite(x, y, amount int) *Scenario {
+	s.halite[y][x] = amount
+	return s
+}
+
+// Fill - Sets the halite of every cell
+func (s *Scenario) Fill(amount int) *Scenario {
+	for _, row := range s.halite {
+		for x := range row {
+			row[x] = amount
+		}
+	}
+	return s
+}
+
+// Bank - Sets the halite a player has banked
+func (s *Scenario) Bank(player, amount int) *Scenario {
+	s.banked[player] = amount
+	return s
+}
+
+// Constant - Overrides a game constant, e.g. MAX_TURNS
+func (s *Scenario) Constant(key string, value interface{}) *Scenario {
+	s.constants[key] = fmt.Sprint(value)
+	return s
+}
+
+// Shipyard - Places the shipyard of a player. Players without one get a default spot spread over the board
+func (s *Scenario) Shipyard(player, x, y int) *Scenario {
+	s.shipyards[player] = [2]int{x, y}
+	return s
+}
+
+// Ship - Places a ship carrying cargo and returns its ID. IDs are handed out in order over all players, as the
+// engine does
+func (s *Scenario) Ship(player, x, y, cargo int) int {
+	id := s.nextShip
+	s.nextShip++
+	s.ships = append(s.ships, entity{player, id, x, y, cargo})
+	return id
+}
+
+// Dropoff - Places a dropoff and returns its ID
+func (s *Scenario) Dropoff(player, x, y int) int {
+	id := s.nextDropoff
+	s.nextDropoff++
+	s.dropoffs = append(s.dropoffs, entity{player, id, x, y, 0})
+	return id
+}
+
+// shipyard - where the player's shipyard is, the default spot when none was placed
+func (s *Scenario) shipyard(player int) (int, int) {
+	if pos, ok := s.shipyards[player]; ok {
+		return pos[0], pos[1]
+	}
+	// mirrored across the middle for 2 players, one per quadrant for 4
+	x, y := s.width/4, s.height/2
+	if s.players > 2 {
+		y = s.height / 4
+	}
+	if player%2 == 1 {
+		x = s.width - 1 - x
+	}
+	if player >= 2 {
+		y = s.height - 1 - y
+	}
+	return x, y
+}
+
+func (s *Scenario) validate() error {
+	if s.width <= 0 || s.height <= 0 {
+		return fmt.Errorf("scenario: board is %dx%d", s.width, s.height)
+	}
+	if s.players < 1 || s.me < 0 || s.me >= s.players {
+		return fmt.Errorf("scenario: player %d of %d", s.me, s.players)
+	}
+	inside := func(what string, player, x, y int) error {
+		if player < 0 || player >= s.players {
+			return fmt.Errorf("scenario: %s of player %d, the game has %d players", what, player, s.players)
+		}
+		if x < 0 || y < 0 || x >= s.width || y >= s.height {
+			return fmt.Errorf("scenario: %s at %d,%d is off the %dx%d board", what, x, y, s.width, s.height)
+		}
+		return nil
+	}
+	for player, pos := range s.shipyards {
+		if err := inside("shipyard", player, pos[0], pos[1]); err != nil {
+			return err
+		}
+	}
+	taken := make(map[[2]int]int)
+	for _, e := range s.ships {
+		if err := inside("ship", e.player, e.x, e.y); err != nil {
+			return err
+		}
+		if other, ok := taken[[2]int{e.x, e.y}]; ok {
+			return fmt.Errorf("scenario: ships %d and %d are both at %d,%d", other, e.id, e.x, e.y)
+		}
+		taken[[2]int{e.x, e.y}] = e.id
+	}
+	for _, e := range s.dropoffs {
+		if err := inside("dropoff", e.player, e.x, e.y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Input - Returns the scenario as the engine would send it: the game header followed by a single frame
+func (s *Scenario) Input() string {
+	var b bytes.Buffer
+	keys := make([]string, 0, len(s.constants))
+	for k := range s.constants {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%q:%s", k, s.constants[k])
+	}
+	fmt.Fprintf(&b, "{%s}\n", strings.Join(pairs, ","))
+	fmt.Fprintf(&b, "%d %d\n", s.players, s.me)
+	for p := 0; p < s.players; p++ {
+		x, y := s.shipyard(p)
+		fmt.Fprintf(&b, "%d %d %d\n", p, x, y)
+	}
+	fmt.Fprintf(&b, "%d %d\n", s.width, s.height)
+	for _, row := range s.halite {
+		for x, h := range row {
+			if x > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprint(&b, h)
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d\n", s.turn)
+	for p := 0; p < s.players; p++ {
+		var ships, dropoffs []entity
+		for _, e := range s.ships {
+			if e.player == p {
+				ships = append(ships, e)
+			}
+		}
+		for _, e := range s.dropoffs {
+			if e.player == p {
+				dropoffs = append(dropoffs, e)
+			}
+		}
+		fmt.Fprintf(&b, "%d %d %d %d\n", p, len(ships), len(dropoffs), s.banked[p])
+		for _, e := range ships {
+			fmt.Fprintf(&b, "%d %d %d %d\n", e.id, e.x, e.y, e.cargo)
+		}
+		for _, e := range dropoffs {
+			fmt.Fprintf(&b, "%d %d %d\n", e.id, e.x, e.y)
+		}
+	}
+	// no cell updates, the board above already is this turn's halite
+	fmt.Fprintln(&b, 0)
+	return b.String()
+}
+
+// Game - Builds the hlt.Game of the scenario. The game constants are replaced by the scenario's, so only one
+// scenario game should be in use at a time
+func (s *Scenario) Game() (*hlt.Game, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	gameconfig.Reset()
+	input.SetSource(strings.NewReader(s.Input()))
+	game := hlt.NewGame()
+	if game == nil {
+		return nil, fmt.Errorf("scenario: could not read the game")
+	}
+	if gameconfig.GetInstance() == nil {
+		return nil, fmt.Errorf("scenario: could not read the game constants")
+	}
+	if err := game.UpdateFrame(); err != nil {
+		return nil, fmt.Errorf("scenario: could not read the frame: %v", err)
+	}
+	return game, nil
+}
+
+// World - The scenario game with the bot logic set up for its turn
+type World struct {
+	Game    *hlt.Game
+	AI      *logic.GameAI
+	MoveAI  *logic.MoveAI
+	Convert *logic.ConvertAI
+}
+
+// World - Builds the game and runs the bot's map analysis and start of turn update on it. Nil params plays with the defaults
+// and the profile for the board
+func (s *Scenario) World(params *logic.Params) (*World, error) {
+	game, err := s.Game()
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = logic.DefaultParams()
+		params.ApplyProfile(game.Map.Width(), game.NumPlayers())
+	}
+	if err := params.Check(); err != nil {
+		return nil, err
+	}
+	ai := logic.NewGameAI(game, gameconfig.GetInstance(), params)
+	ai.Analyze(time.Now().Add(time.Duration(params.InitAnalysisMillis) * time.Millisecond))
+	ai.Update(ai.NewDeadline())
+	return &World{
+		Game:    game,
+		AI:      ai,
+		MoveAI:  logic.NewMoveAI(ai, game.Map, game.Me),
+		Convert: logic.NewConvertAI(ai),
+	}, nil
+}
+
+// Ship - Returns the ship with the ID, whoever owns it
+func (w *World) Ship(id int) *hlt.Ship {
+	for _, p := range w.Game.Players() {
+		if ship, ok := p.Ships[id]; ok {
+			return ship
+		}
+	}
+	return nil
+}
+
+// Decision - Returns what GameAI.ShipLogic decides for one of our ships
+func (w *World) Decision(id int) (logic.ShipDecision, error) {
+	ship, ok := w.Game.Me.Ships[id]
+	if !ok {
+		return 0, fmt.Errorf("scenario: we have no ship %d", id)
+	}
+	return w.AI.ShipLogic(ship), nil
+}
+
+// Move - Returns the command MoveAI.Move gives one of our ships. Ships moved earlier keep their claimed cells
+func (w *World) Move(id int) (hlt.Command, error) {
+	ship, ok := w.Game.Me.Ships[id]
+	if !ok {
+		return nil, fmt.Errorf("scenario: we have no ship %d", id)
+	}
+	return w.MoveAI.Move(ship), nil
+}
+
+// Dropoff - Returns the conversion ConvertAI.DeterminePossibleDropOff picks, nil when no ship converts
+func (w *World) Dropoff() hlt.Command {
+	return w.Convert.DeterminePossibleDropOff(w.Game.Me.Ships)
+}
+
+// Turn - Plays the whole turn like the bot does with GameAI.PlanTurn. Call it on a fresh World, it shares the
+// claimed cells with Move
+func (w *World) Turn() []hlt.Command {
+	return w.AI.PlanTurn(w.MoveAI, w.Convert)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"render"
+	"scenario"
+	"sort"
+)
+
+// fixtureFiles - the .txt files among the arguments, directories are searched one level deep
+func fixtureFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func main() {
+	var (
+		verbose = flag.Bool("v", false, "draw the board of every fixture and list the commands of its turn")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] [fixture.txt or directory...]\n\nChecks the bot against scenario fixtures, the scenarios directory when none are given.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"scenarios"}
+	}
+	files, err := fixtureFiles(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	failed := 0
+	for _, path := range files {
+		fixture, err := scenario.Load(path)
+		if err == nil && *verbose {
+			var world *scenario.World
+			params, _ := fixture.NewParams()
+			if world, err = fixture.World(params); err == nil {
+				commands := world.Turn()
+				fmt.Print(render.ASCII(world.Game, render.Options{Commands: commands}))
+				for _, com := range commands {
+					fmt.Printf("  %s\n", com.CommandString())
+				}
+			}
+		}
+		var failures []string
+		if err == nil {
+			failures, err = fixture.Check()
+		}
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", path, err)
+		case len(failures) > 0:
+			failed++
+			fmt.Printf("FAIL %s\n", path)
+			for _, f := range failures {
+				fmt.Printf("    %s\n", f)
+			}
+		default:
+			fmt.Printf("ok   %s (%d expectations)\n", path, len(fixture.Expect))
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d fixtures failed\n", failed, len(files))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"games"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// entrant - One bot in the tournament
+type entrant struct {
+	name    string
+	command string
+	games   int
+	firsts  int
+	rankSum int
+	score   int
+	errors  int
+}
+
+// botFlags - repeated -bot name=command flags
+type botFlags []*entrant
+
+func (b *botFlags) String() string {
+	names := make([]string, len(*b))
+	for i, e := range *b {
+		names[i] = e.name
+	}
+	return strings.Join(names, ",")
+}
+
+func (b *botFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return fmt.Errorf("expected name=command, got %q", value)
+	}
+	*b = append(*b, &entrant{name: value[:i], command: value[i+1:]})
+	return nil
+}
+
+// combinations - every way to pick k of n entrants, in order
+func combinations(n, k int) [][]int {
+	var all [][]int
+	var pick func(start int, chosen []int)
+	pick = func(start int, chosen []int) {
+		if len(chosen) == k {
+			all = append(all, append([]int(nil), chosen...))
+			return
+		}
+		for i := start; i < n; i++ {
+			pick(i+1, append(chosen, i))
+		}
+	}
+	pick(0, nil)
+	return all
+}
+
+// schedule - round robin: every group of entrants plays the same maps once per seat rotation, so no one gets the
+// better start on a map more often than the others
+func schedule(n int, playerCounts, sizes []int, perGroup, turns int, rng *rand.Rand) ([]games.Match, [][]int) {
+	var matches []games.Match
+	var seats [][]int
+	for _, players := range playerCounts {
+		for _, group := range combinations(n, players) {
+			for g := 0; g < perGroup; g++ {
+				seed := rng.Int63n(math.MaxInt32)
+				size := sizes[rng.Intn(len(sizes))]
+				for rot := 0; rot < players; rot++ {
+					seat := make([]int, players)
+					for p := range seat {
+						seat[p] = group[(p+rot)%players]
+					}
+					matches = append(matches, games.Match{Seed: seed, Size: size, Turns: turns})
+					seats = append(seats, seat)
+				}
+			}
+		}
+	}
+	return matches, seats
+}
+
+func main() {
+	var (
+		bots       botFlags
+		enginePath = flag.String("engine", "./halite", "path to the halite engine binary")
+		botBinary  = flag.String("bot-binary", "./bot", "bot binary the -strategies entrants run")
+		strategies = flag.String("strategies", "", "comma separated strategy presets to enter, each as the bot binary with -strategy <name>")
+		players    = flag.String("players", "2,4", "comma separated player counts to play, 4 player games need 4 entrants")
+		sizes      = flag.String("sizes", "32,40,48,56,64", "comma separated map sizes to pick from")
+		perGroup   = flag.Int("games", 4, "maps every pairing or group of four plays, each once per seat rotation")
+		turns      = flag.Int("turns", 0, "turn limit per game, zero keeps the engine default")
+		workers    = flag.Int("workers", 0, "games played at once, zero uses every CPU core")
+		seed       = flag.Int64("seed", time.Now().UnixNano(), "seed for the map seeds and the bootstrap")
+		timeout    = flag.Duration("timeout", 5*time.Minute, "kill a game running longer than this")
+		replayDir  = flag.String("replay-dir", "", "keep the replays in this directory")
+		bootstrap  = flag.Int("bootstrap", 500, "bootstrap rounds for the rating confidence intervals")
+		level      = flag.Float64("level", 0.95, "confidence level of the rating intervals")
+		csvFile    = flag.String("csv", "", "write a row per game to this CSV file")
+	)
+	flag.Var(&bots, "bot", "entrant as name=command, e.g. -bot new=\"./bot\" -bot old=\"./bot-old -strategy safe\", repeatable")
+	flag.Parse()
+
+	if *strategies != "" {
+		for _, s := range strings.Split(*strategies, ",") {
+			s = strings.TrimSpace(s)
+			bots = append(bots, &entrant{name: s, command: *botBinary + " -strategy " + s})
+		}
+	}
+	if len(bots) < 2 {
+		fatal(fmt.Errorf("need at least two entrants from -bot or -strategies"))
+	}
+	seen := make(map[string]bool)
+	for _, e := range bots {
+		if seen[e.name] {
+			fatal(fmt.Errorf("entrant %q entered twice", e.name))
+		}
+		seen[e.name] = true
+	}
+	var playerCounts []int
+	for _, p := range parseInts("-players", *players) {
+		if p != 2 && p != 4 {
+			fatal(fmt.Errorf("-players: games are 2 or 4 players, got %d", p))
+		}
+		if p > len(bots) {
+			fmt.Fprintf(os.Stderr, "tournament: skipping %d player games, there are only %d entrants\n", p, len(bots))
+			continue
+		}
+		playerCounts = append(playerCounts, p)
+	}
+	if len(playerCounts) == 0 {
+		fatal(fmt.Errorf("no games to play"))
+	}
+	if *replayDir != "" {
+		if err := os.MkdirAll(*replayDir, 0755); err != nil {
+			fatal(err)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	matches, seats := schedule(len(bots), playerCounts, parseInts("-sizes", *sizes), *perGroup, *turns, rng)
+	for i := range matches {
+		matches[i].Bots = make([]string, len(seats[i]))
+		for p, e := range seats[i] {
+			matches[i].Bots[p] = bots[e].command
+		}
+	}
+	fmt.Printf("%d entrants, %d games\n", len(bots), len(matches))
+	engine := &games.Engine{Path: *enginePath, ReplayDir: *replayDir, Timeout: *timeout}
+	played := 0
+	results := engine.PlayAll(matches, *workers, func(r *games.Result) {
+		played++
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+		}
+		if played%10 == 0 || played == len(matches) {
+			fmt.Fprintf(os.Stderr, "%d/%d games played\n", played, len(matches))
+		}
+	})
+
+	n := len(bots)
+	var outcomes []games.Outcome
+	var byMatch [][]games.Outcome
+	// headToHead[i][j] - pairwise wins of i over j, 4 player games count every pair and a draw is half a win each
+	headToHead := make([][]float64, n)
+	for i := range headToHead {
+		headToHead[i] = make([]float64, n)
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			for _, e := range seats[i] {
+				bots[e].errors++
+			}
+			continue
+		}
+		for p, e := range seats[i] {
+			b := bots[e]
+			b.games++
+			b.rankSum += r.Players[p].Rank
+			b.score += r.Players[p].Score
+			if r.Players[p].Rank == 1 {
+				b.firsts++
+			}
+		}
+		o := games.Outcomes(r, seats[i])
+		for _, x := range o {
+			if x.Draw {
+				headToHead[x.Winner][x.Loser] += 0.5
+				headToHead[x.Loser][x.Winner] += 0.5
+			} else {
+				headToHead[x.Winner][x.Loser]++
+			}
+		}
+		outcomes = append(outcomes, o...)
+		byMatch = append(byMatch, o)
+	}
+	if len(byMatch) == 0 {
+		fatal(fmt.Errorf("every game failed"))
+	}
+
+	ratings := games.Ratings(n, outcomes)
+	lo, hi := games.RatingIntervals(n, byMatch, *bootstrap, *level, rng)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ratings[order[a]] > ratings[order[b]]
+	})
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "rank\tname\telo\t%.0f%% interval\tgames\tfirsts\tavg_rank\tavg_score\terrors\t\n", *level*100)
+	for rank, i := range order {
+		b := bots[i]
+		avgRank, avgScore := "-", "-"
+		if b.games > 0 {
+			avgRank = strconv.FormatFloat(float64(b.rankSum)/float64(b.games), 'f', 2, 64)
+			avgScore = strconv.Itoa(b.score / b.games)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%.0f\t%.0f..%.0f\t%d\t%d\t%s\t%s\t%d\t\n", rank+1, b.name, ratings[i], lo[i], hi[i],
+			b.games, b.firsts, avgRank, avgScore, b.errors)
+	}
+	tw.Flush()
+
+	fmt.Println("\nhead to head, wins of the row over the column:")
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t")
+	for _, j := range order {
+		fmt.Fprintf(tw, "%s\t", bots[j].name)
+	}
+	fmt.Fprintln(tw)
+	for _, i := range order {
+		fmt.Fprintf(tw, "%s\t", bots[i].name)
+		for _, j := range order {
+			if i == j {
+				fmt.Fprint(tw, "-\t")
+				continue
+			}
+			total := headToHead[i][j] + headToHead[j][i]
+			if total == 0 {
+				fmt.Fprint(tw, "\t")
+				continue
+			}
+			fmt.Fprintf(tw, "%g-%g (%.0f%%)\t", headToHead[i][j], headToHead[j][i], 100*headToHead[i][j]/total)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	if *csvFile != "" {
+		f, err := os.Create(*csvFile)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		if err := writeCSV(f, bots, results, seats); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// writeCSV - one row per game with the entrant, rank and score of every seat
+func writeCSV(w io.Writer, bots []*entrant, results []*games.Result, seats [][]int) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"seed", "size", "players", "seat", "name", "rank", "score", "error"})
+	for i, r := range results {
+		for p, e := range seats[i] {
+			row := []string{strconv.FormatInt(r.Match.Seed, 10), strconv.Itoa(r.Match.Size), strconv.Itoa(len(seats[i])),
+				strconv.Itoa(p), bots[e].name}
+			if r.Err != nil {
+				row = append(row, "", "", r.Err.Error())
+			} else {
+				row = append(row, strconv.Itoa(r.Players[p].Rank), strconv.Itoa(r.Players[p].Score), "")
+			}
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func parseInts(name, list string) []int {
+	var values []int
+	for _, s := range strings.Split(list, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			fatal(fmt.Errorf("%s: %v", name, err))
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "tournament:", err)
+	os.Exit(1)
+}
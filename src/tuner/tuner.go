@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"games"
+	"io/ioutil"
+	"logic"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range - Values a parameter may take while tuning
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// defaultSpace - the knobs we never had time to tune by hand, mostly the dropoff formulas
+func defaultSpace() map[string]Range {
+	return map[string]Range{
+		"dropoff_distance_point":     {8, 20},
+		"dropoff_distance_threshold": {0, 5},
+		"dropoff_site_radius":        {3, 9},
+		"dropoff_value_ratio":        {1, 4},
+		"dropoff_ships_per_dock":     {3, 12},
+		"return_min_cargo_ratio":     {0.3, 0.9},
+		"return_lookahead":           {2, 10},
+		"spawn_profit_padding":       {0.8, 2},
+		"flee_cargo_ratio":           {0.2, 0.9},
+	}
+}
+
+// candidate - One point in the parameter space and how it did in the current generation
+type candidate struct {
+	id        int
+	values    map[string]float64
+	file      string
+	games     int
+	wins      int
+	scoreDiff float64
+	errors    int
+}
+
+func (c *candidate) winRate() float64 {
+	if c.games == 0 {
+		return 0
+	}
+	return float64(c.wins) / float64(c.games)
+}
+
+func (c *candidate) meanScoreDiff() float64 {
+	if c.games == 0 {
+		return 0
+	}
+	return c.scoreDiff / float64(c.games)
+}
+
+func (c *candidate) fitness(scoreWeight float64) float64 {
+	return c.winRate() + scoreWeight*c.meanScoreDiff()
+}
+
+type tuner struct {
+	engine      *games.Engine
+	bot         string
+	space       map[string]Range
+	names       []string
+	rng         *rand.Rand
+	workDir     string
+	sizes       []int
+	players     int
+	games       int
+	turns       int
+	workers     int
+	sigma       float64
+	scoreWeight float64
+	nextID      int
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "tuner:", err)
+		os.Exit(1)
+	}
+}
+
+// run - the whole search, errors come back here so the work dir is always cleaned up
+func run() error {
+	var (
+		enginePath  = flag.String("engine", "./halite", "path to the halite engine binary")
+		bot         = flag.String("bot", "./bot", "bot binary to tune, it is started with -params <file>")
+		spaceFile   = flag.String("space", "", "JSON file mapping parameter names to {\"min\": .., \"max\": ..}, defaults to the dropoff and return knobs")
+		baseline    = flag.String("baseline", "", "params file the opponents play with, empty plays against the defaults")
+		againstBest = flag.Bool("against-best", false, "replace the opponents with the best candidate whenever it beats them")
+		generations = flag.Int("generations", 20, "generations to run")
+		population  = flag.Int("population", 12, "candidates per generation")
+		elite       = flag.Int("elite", 4, "best candidates carried over to the next generation")
+		numGames    = flag.Int("games", 10, "games every candidate plays per generation")
+		players     = flag.Int("players", 2, "players per game, 2 or 4")
+		sizes       = flag.String("sizes", "32,40,48,56,64", "comma separated map sizes to pick from")
+		turns       = flag.Int("turns", 0, "turn limit per game, zero keeps the engine default")
+		workers     = flag.Int("workers", 0, "games played at once, zero uses every CPU core")
+		sigma       = flag.Float64("sigma", 0.15, "mutation step as a share of each parameter range")
+		scoreWeight = flag.Float64("score-weight", 0.5, "weight of the mean relative score difference next to the win rate")
+		seed        = flag.Int64("seed", time.Now().UnixNano(), "seed for the search and the map seeds")
+		timeout     = flag.Duration("timeout", 5*time.Minute, "kill a game running longer than this")
+		out         = flag.String("out", "best_params.json", "where to write the best parameters")
+		csvFile     = flag.String("csv", "trials.csv", "where to write every trial")
+		workDir     = flag.String("work-dir", "", "directory for candidate params files, a temporary one when empty")
+	)
+	flag.Parse()
+
+	space := defaultSpace()
+	if *spaceFile != "" {
+		data, err := ioutil.ReadFile(*spaceFile)
+		if err != nil {
+			return err
+		}
+		space = make(map[string]Range)
+		if err := json.Unmarshal(data, &space); err != nil {
+			return fmt.Errorf("%s: %v", *spaceFile, err)
+		}
+	}
+	t := &tuner{
+		engine:      &games.Engine{Path: *enginePath, Timeout: *timeout},
+		bot:         *bot,
+		space:       space,
+		rng:         rand.New(rand.NewSource(*seed)),
+		players:     *players,
+		games:       *numGames,
+		turns:       *turns,
+		workers:     *workers,
+		sigma:       *sigma,
+		scoreWeight: *scoreWeight,
+	}
+	for name, r := range space {
+		if _, err := logic.DefaultParams().Get(name); err != nil {
+			return err
+		}
+		if r.Max < r.Min {
+			return fmt.Errorf("%s: max %g is below min %g", name, r.Max, r.Min)
+		}
+		// both ends have to be values the bot accepts, mutations are clamped to them
+		for _, v := range []float64{r.Min, r.Max} {
+			if logic.DefaultParams().IsInt(name) {
+				v = math.Round(v)
+			}
+			if err := logic.DefaultParams().Set(name, strconv.FormatFloat(v, 'g', -1, 64)); err != nil {
+				return fmt.Errorf("-space: %v", err)
+			}
+		}
+		t.names = append(t.names, name)
+	}
+	sort.Strings(t.names)
+	for _, s := range strings.Split(*sizes, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("-sizes: %v", err)
+		}
+		t.sizes = append(t.sizes, size)
+	}
+	if *players != 2 && *players != 4 {
+		return fmt.Errorf("-players must be 2 or 4")
+	}
+	if *elite >= *population || *elite < 1 {
+		return fmt.Errorf("-elite must be between 1 and the population size")
+	}
+	t.workDir = *workDir
+	if t.workDir == "" {
+		dir, err := ioutil.TempDir("", "tuner")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		t.workDir = dir
+	}
+
+	trials, err := os.Create(*csvFile)
+	if err != nil {
+		return err
+	}
+	defer trials.Close()
+	w := csv.NewWriter(trials)
+	header := append([]string{"generation", "candidate"}, t.names...)
+	w.Write(append(header, "games", "wins", "errors", "win_rate", "score_diff", "fitness"))
+
+	opponent := *baseline
+	first, err := t.seedCandidate(opponent)
+	if err != nil {
+		return err
+	}
+	pop := []*candidate{first}
+	for len(pop) < *population {
+		c, err := t.randomCandidate()
+		if err != nil {
+			return err
+		}
+		pop = append(pop, c)
+	}
+	var best *candidate
+	for gen := 0; gen < *generations; gen++ {
+		t.evaluate(pop, opponent)
+		sort.SliceStable(pop, func(i, j int) bool {
+			return pop[i].fitness(t.scoreWeight) > pop[j].fitness(t.scoreWeight)
+		})
+		for _, c := range pop {
+			row := []string{strconv.Itoa(gen), strconv.Itoa(c.id)}
+			for _, name := range t.names {
+				row = append(row, strconv.FormatFloat(c.values[name], 'g', -1, 64))
+			}
+			row = append(row, strconv.Itoa(c.games), strconv.Itoa(c.wins), strconv.Itoa(c.errors),
+				strconv.FormatFloat(c.winRate(), 'f', 4, 64),
+				strconv.FormatFloat(c.meanScoreDiff(), 'f', 4, 64),
+				strconv.FormatFloat(c.fitness(t.scoreWeight), 'f', 4, 64))
+			w.Write(row)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		best = pop[0]
+		fmt.Printf("generation %d: best candidate %d win rate %.2f score diff %+.3f fitness %.3f\n",
+			gen, best.id, best.winRate(), best.meanScoreDiff(), best.fitness(t.scoreWeight))
+		if err := copyFile(best.file, *out); err != nil {
+			return err
+		}
+		if *againstBest && best.winRate() > 0.5 {
+			fmt.Printf("generation %d: candidate %d is the new opponent\n", gen, best.id)
+			opponent = best.file
+		}
+
+		next := pop[:*elite]
+		for len(next) < *population {
+			c, err := t.mutate(next[t.rng.Intn(*elite)])
+			if err != nil {
+				return err
+			}
+			next = append(next, c)
+		}
+		pop = next
+	}
+	fmt.Printf("best parameters written to %s, trials to %s\n", *out, *csvFile)
+	return nil
+}
+
+// evaluate - Plays every candidate against the opponent on the same set of maps, so candidates of a generation
+// are compared on equal terms. The candidate swaps seats from game to game
+func (t *tuner) evaluate(pop []*candidate, opponent string) {
+	maps := make([]games.Match, t.games)
+	for i := range maps {
+		maps[i] = games.Match{
+			Seed:  t.rng.Int63n(math.MaxInt32),
+			Size:  t.sizes[t.rng.Intn(len(t.sizes))],
+			Turns: t.turns,
+		}
+	}
+	opponentCmd := t.bot
+	if opponent != "" {
+		opponentCmd = t.bot + " -params " + opponent
+	}
+	matches := make([]games.Match, 0, len(pop)*t.games)
+	seats := make([]int, 0, cap(matches))
+	owners := make([]*candidate, 0, cap(matches))
+	for _, c := range pop {
+		c.games, c.wins, c.scoreDiff, c.errors = 0, 0, 0, 0
+		for i, m := range maps {
+			seat := i % t.players
+			m.Bots = make([]string, t.players)
+			for p := range m.Bots {
+				m.Bots[p] = opponentCmd
+			}
+			m.Bots[seat] = t.bot + " -params " + c.file
+			matches = append(matches, m)
+			seats = append(seats, seat)
+			owners = append(owners, c)
+		}
+	}
+	results := t.engine.PlayAll(matches, t.workers, func(r *games.Result) {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+		}
+	})
+	for i, r := range results {
+		c := owners[i]
+		if r.Err != nil {
+			c.errors++
+			continue
+		}
+		c.games++
+		if r.Won(seats[i]) {
+			c.wins++
+		}
+		c.scoreDiff += r.ScoreDiff(seats[i])
+	}
+}
+
+// seedCandidate - the baseline itself is always part of the first generation. It plays from a copy of the baseline
+// file, so the parameters it leaves to the map size profiles keep following them just like the opponent's do
+func (t *tuner) seedCandidate(baseline string) (*candidate, error) {
+	params := logic.DefaultParams()
+	data := []byte("{}\n")
+	if baseline != "" {
+		var err error
+		if params, err = logic.LoadParams(baseline); err != nil {
+			return nil, err
+		}
+		if data, err = ioutil.ReadFile(baseline); err != nil {
+			return nil, err
+		}
+	}
+	// profile driven values show up as their defaults in the trials and as the starting point of mutations
+	values := make(map[string]float64)
+	for _, name := range t.names {
+		values[name], _ = params.Get(name)
+	}
+	c := t.nextCandidate(values)
+	return c, ioutil.WriteFile(c.file, data, 0644)
+}
+
+func (t *tuner) randomCandidate() (*candidate, error) {
+	values := make(map[string]float64)
+	for _, name := range t.names {
+		r := t.space[name]
+		values[name] = r.Min + t.rng.Float64()*(r.Max-r.Min)
+	}
+	return t.newCandidate(values)
+}
+
+func (t *tuner) mutate(parent *candidate) (*candidate, error) {
+	values := make(map[string]float64)
+	for _, name := range t.names {
+		r := t.space[name]
+		v := parent.values[name] + t.rng.NormFloat64()*t.sigma*(r.Max-r.Min)
+		values[name] = math.Max(r.Min, math.Min(r.Max, v))
+	}
+	return t.newCandidate(values)
+}
+
+// newCandidate - integer parameters are rounded the way the bot would see them, and values the bot refuses are
+// an error rather than a candidate that fails every game
+func (t *tuner) newCandidate(values map[string]float64) (*candidate, error) {
+	params := logic.DefaultParams()
+	for name, v := range values {
+		if params.IsInt(name) {
+			v = math.Round(v)
+		}
+		if err := params.Set(name, strconv.FormatFloat(v, 'g', -1, 64)); err != nil {
+			return nil, err
+		}
+		values[name] = v
+	}
+	if err := params.Check(); err != nil {
+		return nil, err
+	}
+	c := t.nextCandidate(values)
+	return c, writeParams(c.file, values)
+}
+
+// nextCandidate - numbers the candidate and names its params file
+func (t *tuner) nextCandidate(values map[string]float64) *candidate {
+	c := &candidate{id: t.nextID, values: values}
+	t.nextID++
+	c.file = filepath.Join(t.workDir, fmt.Sprintf("candidate-%d.json", c.id))
+	return c
+}
+
+// writeParams - only the tuned parameters are written, so the rest keep following the map size profiles
+func writeParams(path string, values map[string]float64) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// copyFile - the best candidate's params file as it was played
+func copyFile(from, to string) error {
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(to, data, 0644)
+}
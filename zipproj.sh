@@ -2,6 +2,6 @@ rm halite.zip
 
 cp src/main/MyBot.go MyBot.go
 
-zip halite.zip -r MyBot.go src/helper/* src/hlt/* src/logic/* pkg/*
+zip halite.zip -r MyBot.go src/helper/* src/hlt/* src/logic/* src/render/* pkg/*
 
 rm MyBot.go
\ No newline at end of file